@@ -0,0 +1,178 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/songtov/wtt/internal/git"
+	"github.com/songtov/wtt/internal/globalconfig"
+	"github.com/songtov/wtt/internal/meta"
+	"github.com/spf13/cobra"
+)
+
+// writeCompletion writes cobra's completion script for sh, registered for the
+// "wtt" shell function, so that "--init" sets up completion with no extra
+// step. Shells cobra can't complete for get nothing.
+func writeCompletion(root *cobra.Command, w io.Writer, sh string) error {
+	var buf bytes.Buffer
+	var err error
+	switch sh {
+	case "zsh":
+		err = root.GenZshCompletion(&buf)
+	case "bash":
+		err = root.GenBashCompletionV2(&buf, true)
+	case "fish":
+		err = root.GenFishCompletion(&buf, true)
+	case "pwsh", "powershell":
+		err = root.GenPowerShellCompletionWithDesc(&buf)
+	default:
+		return nil // cobra has no completion for this shell
+	}
+	if err != nil {
+		return fmt.Errorf("generating %s completion: %w", sh, err)
+	}
+
+	fmt.Fprint(w, "\n# ── Completion ──────────────────────────────────────────────────────────────\n")
+	if sh == "zsh" {
+		// compdef only exists once compinit has run; without it, skip
+		// completion rather than erroring on every shell start.
+		fmt.Fprint(w, "if (( $+functions[compdef] )); then\n")
+		_, err = buf.WriteTo(w)
+		fmt.Fprint(w, "fi\n")
+		return err
+	}
+	script := buf.String()
+	if sh == "bash" {
+		// cobra's bash script relies on _get_comp_words_by_ref from the
+		// bash-completion package; fall back to a minimal version when that
+		// package isn't installed.
+		fmt.Fprint(w, bashCompWordsFallback)
+		script = strings.ReplaceAll(script, "_get_comp_words_by_ref ", "__wtt_get_comp_words_by_ref ")
+	}
+	_, err = io.WriteString(w, script)
+	return err
+}
+
+const bashCompWordsFallback = `__wtt_get_comp_words_by_ref() {
+  if declare -F _get_comp_words_by_ref >/dev/null 2>&1; then
+    _get_comp_words_by_ref "$@"
+    return
+  fi
+  cur="${COMP_WORDS[COMP_CWORD]}"
+  prev="${COMP_WORDS[COMP_CWORD-1]}"
+  words=("${COMP_WORDS[@]}")
+  cword=$COMP_CWORD
+}
+`
+
+// Dynamic shell completions. These run on every <TAB>, so they never
+// register repos or print anything besides the candidates.
+
+// completeWorktrees completes the branch of every worktree, with its note as
+// the description.
+func completeWorktrees(_ *cobra.Command, args []string, _ string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	return worktreeCompletions(true), cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeRemovableWorktrees is like completeWorktrees without the main worktree.
+func completeRemovableWorktrees(_ *cobra.Command, args []string, _ string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	return worktreeCompletions(false), cobra.ShellCompDirectiveNoFileComp
+}
+
+func worktreeCompletions(includeMain bool) []string {
+	repoRoot, err := repoRootWithFallback()
+	if err != nil {
+		return nil
+	}
+	worktrees, err := git.ListWorktreesIn(repoRoot)
+	if err != nil {
+		return nil
+	}
+	store, _ := meta.Load(repoRoot)
+
+	var comps []string
+	for _, wt := range worktrees {
+		if wt.Branch == "" || (wt.IsMain && !includeMain) {
+			continue
+		}
+		comp := strings.TrimPrefix(wt.Branch, "refs/heads/")
+		if store != nil {
+			if e := store.Get(wt.Path); e != nil && e.Summary() != "" {
+				comp += "\t" + e.Summary()
+			}
+		}
+		comps = append(comps, comp)
+	}
+	return comps
+}
+
+// completeBranches completes local and remote branch names (without the
+// remote prefix), for "wtt create <branch>".
+func completeBranches(_ *cobra.Command, args []string, _ string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	repoRoot, err := repoRootWithFallback()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	local, _ := git.ListRefs(repoRoot, "refs/heads")
+	remote, _ := git.ListRefs(repoRoot, "refs/remotes")
+
+	seen := map[string]bool{}
+	var comps []string
+	add := func(name string) {
+		if name != "" && !seen[name] {
+			seen[name] = true
+			comps = append(comps, name)
+		}
+	}
+	for _, ref := range local {
+		add(ref)
+	}
+	for _, ref := range remote {
+		_, name, _ := strings.Cut(ref, "/")
+		add(name)
+	}
+	return comps, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeRefs completes any branch, remote branch or tag, for --base.
+func completeRefs(_ *cobra.Command, _ []string, _ string) ([]string, cobra.ShellCompDirective) {
+	repoRoot, err := repoRootWithFallback()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	refs, _ := git.ListRefs(repoRoot)
+	return refs, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeRepos completes the names and aliases of known repos, with their
+// path as the description.
+func completeRepos(_ *cobra.Command, args []string, _ string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	repos, err := canonicalRepos()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	var comps []string
+	for _, r := range repos {
+		comps = append(comps, filepath.Base(r)+"\t"+r)
+	}
+	aliases, _ := globalconfig.GetAliases()
+	for name, path := range aliases {
+		comps = append(comps, name+"\t"+path)
+	}
+	return comps, cobra.ShellCompDirectiveNoFileComp
+}
@@ -1,38 +1,247 @@
 package cmd
 
 import (
+	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/songtov/wtt/internal/git"
 	"github.com/songtov/wtt/internal/globalconfig"
+	"github.com/songtov/wtt/internal/meta"
+	"github.com/songtov/wtt/internal/promptcache"
 	"github.com/spf13/cobra"
 )
 
+// defaultContextFormat shows the repo name, plus the branch when inside a
+// linked worktree.
+const defaultContextFormat = "{repo}{worktree: [%s]}"
+
+var (
+	contextFormat string
+	contextJSON   bool
+)
+
+func init() {
+	contextCmd.Flags().StringVar(&contextFormat, "format", defaultContextFormat, "Output template; see 'wtt context --help' for placeholders")
+	contextCmd.Flags().BoolVar(&contextJSON, "json", false, "Print every field as a JSON object")
+	_ = contextCmd.RegisterFlagCompletionFunc("format", completeContextFormat)
+}
+
+// contextPlaceholders are the fields --format accepts, with a description
+// for completion.
+var contextPlaceholders = []string{
+	"repo\trepo name",
+	"branch\tbranch checked out in the current worktree",
+	"worktree\tbranch of the current worktree, only inside a linked worktree",
+	"source\twhere the repo came from",
+	"dirty\t* when the worktree has uncommitted changes",
+	"note\tthe worktree's note and labels",
+}
+
+// completeContextFormat completes --format by appending a placeholder to
+// what has been typed so far.
+func completeContextFormat(_ *cobra.Command, _ []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	prefix := toComplete
+	if i := strings.LastIndex(toComplete, "{"); i >= 0 && !strings.Contains(toComplete[i:], "}") {
+		prefix = toComplete[:i]
+	}
+	comps := make([]string, len(contextPlaceholders))
+	for i, p := range contextPlaceholders {
+		comps[i] = prefix + "{" + strings.Replace(p, "\t", "}\t", 1)
+	}
+	return comps, cobra.ShellCompDirectiveNoSpace | cobra.ShellCompDirectiveNoFileComp
+}
+
 var contextCmd = &cobra.Command{
-	Use:    "context",
-	Short:  "Print the current repo context (for shell prompt use)",
-	Long:   `Print the name of the active repo — current git root if inside one, otherwise the saved context set by 'wtt repo'. Exits silently with no output when no context is available. Intended for prompt integrations.`,
+	Use:   "context",
+	Short: "Print the current repo context (for shell prompt use)",
+	Long: `Print the active repo — the repo of the current directory if inside one,
+otherwise the context set by 'wtt repo use' for this shell session, or the
+global one. Exits silently with no output
+when no context is available. Intended for prompt integrations.
+
+Placeholders for --format:
+  {repo}      repo name
+  {branch}    branch checked out in the current worktree
+  {worktree}  branch of the current worktree, only inside a linked worktree
+  {source}    "cwd", "session" or "global" — where the repo came from
+  {dirty}     "*" when the worktree has uncommitted changes (runs git)
+  {note}      the worktree's wtt note and labels
+
+{name:text} prints text, with %s replaced by the value, only when the value
+is non-empty, e.g. "{repo}{dirty: %s}".`,
 	Args:   cobra.NoArgs,
 	Hidden: true, // power-user / prompt integration tool
 	RunE:   runContext,
 }
 
+// promptContext holds the fields the prompt segment can show. Fields that
+// are costly to compute are filled in only when needed.
+type promptContext struct {
+	Repo         string `json:"repo"`
+	RepoRoot     string `json:"repo_root"`
+	Source       string `json:"source"`
+	Branch       string `json:"branch,omitempty"`
+	Worktree     string `json:"worktree,omitempty"`
+	WorktreePath string `json:"worktree_path,omitempty"`
+	Dirty        bool   `json:"dirty"`
+	Note         string `json:"note,omitempty"`
+
+	loc *git.Location
+}
+
 func runContext(_ *cobra.Command, _ []string) error {
-	// 1. Prefer the main repo root of the current directory
-	root, err := git.MainRepoRoot()
-	if err == nil {
-		fmt.Println(filepath.Base(root))
+	ctx := currentPromptContext()
+	if ctx == nil {
+		// No context at all — output nothing, exit 0 (prompt stays clean)
 		return nil
 	}
 
-	// 2. Fall back to the saved context
-	saved, err := globalconfig.GetCurrentRepo()
-	if err != nil || saved == "" {
-		// No context at all — output nothing, exit 0 (prompt stays clean)
-		os.Exit(0)
+	if contextJSON {
+		ctx.fillDirty()
+		ctx.fillNote()
+		return json.NewEncoder(os.Stdout).Encode(ctx)
+	}
+
+	out, err := renderContext(contextFormat, ctx)
+	if err != nil {
+		return err
+	}
+	if out != "" {
+		fmt.Println(out)
 	}
-	fmt.Println(filepath.Base(saved))
 	return nil
 }
+
+// currentPromptContext returns the context for the current directory, or
+// the saved one, or nil.
+func currentPromptContext() *promptContext {
+	// 1. Prefer the repo of the current directory
+	if loc := promptLocation(); loc != nil {
+		ctx := &promptContext{
+			Repo:     filepath.Base(loc.MainRoot),
+			RepoRoot: loc.MainRoot,
+			Source:   "cwd",
+			Branch:   loc.Branch(),
+			loc:      loc,
+		}
+		if loc.IsLinked() {
+			ctx.Worktree = ctx.Branch
+			ctx.WorktreePath = loc.Worktree
+		}
+		return ctx
+	}
+
+	// 2. Fall back to the session's context, then the global one
+	source := "session"
+	saved, ok := globalconfig.SessionRepo()
+	if !ok {
+		source = "global"
+		saved, _ = globalconfig.GetGlobalRepo()
+	}
+	if saved == "" {
+		return nil
+	}
+	return &promptContext{Repo: filepath.Base(saved), RepoRoot: saved, Source: source}
+}
+
+// promptLocation locates the current directory in its repo, or returns nil
+// outside a repo. It runs on every prompt, so it never spawns git: the
+// location comes from the prompt cache or from walking up to the .git entry.
+func promptLocation() *git.Location {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return nil
+	}
+	if loc, ok := promptcache.Lookup(cwd); ok {
+		return loc
+	}
+
+	dir := cwd
+	if resolved, err := filepath.EvalSymlinks(cwd); err == nil {
+		dir = resolved // match the physical paths git reports
+	}
+	loc, err := git.Locate(dir)
+	if err != nil {
+		return nil
+	}
+	promptcache.Store(cwd, loc)
+	return loc
+}
+
+func (c *promptContext) fillDirty() {
+	if c.loc == nil {
+		return
+	}
+	c.Dirty, _ = git.IsDirty(c.loc.Worktree)
+}
+
+func (c *promptContext) fillNote() {
+	if c.loc == nil {
+		return
+	}
+	store, err := meta.LoadCommon(c.loc.CommonDir)
+	if err != nil {
+		return
+	}
+	if e := store.Get(c.loc.Worktree); e != nil {
+		c.Note = e.Summary()
+	}
+}
+
+// renderContext expands the placeholders in format.
+func renderContext(format string, c *promptContext) (string, error) {
+	var sb strings.Builder
+	for {
+		start := strings.IndexByte(format, '{')
+		if start < 0 {
+			sb.WriteString(format)
+			return sb.String(), nil
+		}
+		end := strings.IndexByte(format[start:], '}')
+		if end < 0 {
+			return "", fmt.Errorf("unterminated placeholder in format %q", format)
+		}
+		end += start
+		sb.WriteString(format[:start])
+
+		name, text, hasText := strings.Cut(format[start+1:end], ":")
+		value, err := c.field(name)
+		if err != nil {
+			return "", err
+		}
+		switch {
+		case value == "":
+		case hasText:
+			sb.WriteString(strings.ReplaceAll(text, "%s", value))
+		default:
+			sb.WriteString(value)
+		}
+		format = format[end+1:]
+	}
+}
+
+func (c *promptContext) field(name string) (string, error) {
+	switch name {
+	case "repo":
+		return c.Repo, nil
+	case "branch":
+		return c.Branch, nil
+	case "worktree":
+		return c.Worktree, nil
+	case "source":
+		return c.Source, nil
+	case "dirty":
+		c.fillDirty()
+		if c.Dirty {
+			return "*", nil
+		}
+		return "", nil
+	case "note":
+		c.fillNote()
+		return c.Note, nil
+	}
+	return "", fmt.Errorf("unknown placeholder {%s}", name)
+}
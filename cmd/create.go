@@ -5,30 +5,62 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strings"
+	"time"
 
 	"github.com/songtov/wtt/internal/config"
 	"github.com/songtov/wtt/internal/git"
+	"github.com/songtov/wtt/internal/meta"
 	"github.com/songtov/wtt/internal/namegen"
 	"github.com/songtov/wtt/internal/worktree"
 	"github.com/spf13/cobra"
 )
 
-var createBase string
+var (
+	createBase   string
+	createNote   string
+	createLabels []string
+	createInWS   string
+)
 
 func init() {
 	createCmd.Flags().StringVarP(&createBase, "base", "b", "", "Base commit/branch/ref to create the worktree from (default: HEAD)")
+	createCmd.Flags().StringVarP(&createNote, "note", "m", "", "Free-text note to attach to the worktree")
+	createCmd.Flags().StringSliceVarP(&createLabels, "label", "l", nil, "Label to attach to the worktree (repeatable)")
+	createCmd.Flags().BoolVar(&keepSubdir, "keep-subdir", false, keepSubdirUsage)
+	createCmd.Flags().StringVarP(&createInWS, "workspace", "w", "", workspaceUsage)
+	_ = createCmd.RegisterFlagCompletionFunc("base", completeRefs)
+	_ = createCmd.RegisterFlagCompletionFunc("workspace", completeWorkspaces)
 }
 
 var createCmd = &cobra.Command{
 	Use:   "create [branch]",
 	Short: "Create a new worktree",
 	Long: `Create a new git worktree for the given branch.
-If no branch name is given, a random name is generated.`,
-	Args: cobra.MaximumNArgs(1),
-	RunE: runCreate,
+If no branch name is given, a random name is generated. A branch that already
+exists locally or on a remote is checked out instead of created.
+
+With --workspace, a worktree for the branch is created in every repo of the
+workspace, side by side under <workspace_dir>/<workspace>/<branch>/, each set
+up by its own repo's .wtt.toml. If any repo fails, the worktrees already
+created are removed again.`,
+	Args:              cobra.MaximumNArgs(1),
+	ValidArgsFunction: completeBranches,
+	RunE:              runCreate,
 }
 
 func runCreate(cmd *cobra.Command, args []string) error {
+	if createInWS != "" {
+		branch := namegen.Generate(createInWS)
+		if len(args) == 1 {
+			branch = args[0]
+			if err := git.ValidateBranchName(branch); err != nil {
+				return err
+			}
+		}
+		return createWorkspace(createInWS, branch)
+	}
+
 	repoRoot, err := repoRootWithFallback()
 	if err != nil {
 		return err
@@ -52,28 +84,107 @@ func runCreate(cmd *cobra.Command, args []string) error {
 		}
 	}
 
-	// Resolve worktree base dir relative to repoRoot
-	worktreeBaseDir := cfg.WorktreeDir
-	if !filepath.IsAbs(worktreeBaseDir) {
-		worktreeBaseDir = filepath.Clean(filepath.Join(repoRoot, worktreeBaseDir))
+	worktreePath, err := createWorktree(repoRoot, cfg, branch, createBase)
+	if err != nil {
+		return err
 	}
 
-	fmt.Fprintf(os.Stderr, "Creating worktree for branch %q...\n", branch)
+	return navigate(repoRoot, worktreePath)
+}
+
+// createWorktree adds a worktree for branch and sets it up according to cfg:
+// files are copied and symlinked, then post_create commands run. An existing
+// local branch is checked out as-is, a branch that only exists on a remote is
+// checked out as a new tracking branch, and anything else becomes a new
+// branch started from base. Returns the new worktree's path.
+func createWorktree(repoRoot string, cfg *config.Config, branch, base string) (string, error) {
+	worktreePath := worktree.Path(worktreeBaseDir(repoRoot, cfg), branch)
+	if err := createWorktreeAt(repoRoot, cfg, branch, base, worktreePath); err != nil {
+		return "", err
+	}
+	return worktreePath, nil
+}
 
-	worktreePath, err := worktree.Create(repoRoot, worktreeBaseDir, branch, createBase)
+// worktreeBaseDir returns the absolute directory new worktrees go in:
+// worktree_dir, resolved relative to repoRoot.
+func worktreeBaseDir(repoRoot string, cfg *config.Config) string {
+	if filepath.IsAbs(cfg.WorktreeDir) {
+		return cfg.WorktreeDir
+	}
+	return filepath.Clean(filepath.Join(repoRoot, cfg.WorktreeDir))
+}
+
+// createWorktreeAt is createWorktree with the worktree placed at
+// worktreePath instead of under the configured worktree_dir.
+func createWorktreeAt(repoRoot string, cfg *config.Config, branch, base, worktreePath string) error {
+	if err := addWorktreeAt(repoRoot, branch, base, worktreePath); err != nil {
+		return err
+	}
+	return setUpWorktree(repoRoot, cfg, worktreePath, false)
+}
+
+// addWorktreeAt adds the worktree for branch at worktreePath and records its
+// metadata, without the copies and hooks of setUpWorktree.
+func addWorktreeAt(repoRoot, branch, base, worktreePath string) error {
+	var err error
+	if git.BranchExists(repoRoot, branch) {
+		if base != "" {
+			return fmt.Errorf("branch %q already exists; --base only applies to new branches", branch)
+		}
+		fmt.Fprintf(os.Stderr, "Creating worktree for existing branch %q...\n", branch)
+		err = worktree.Checkout(repoRoot, worktreePath, branch, "")
+	} else if remote := git.RemoteBranch(repoRoot, branch); remote != "" && base == "" {
+		fmt.Fprintf(os.Stderr, "Creating worktree for branch %q tracking %s...\n", branch, remote)
+		err = worktree.Checkout(repoRoot, worktreePath, branch, remote)
+		base = remote
+	} else {
+		fmt.Fprintf(os.Stderr, "Creating worktree for branch %q...\n", branch)
+		err = worktree.Create(repoRoot, worktreePath, branch, base)
+		if err == nil && base == "" {
+			// git worktree add starts from the main worktree's HEAD; record
+			// its upstream if it has one, so sync follows the remote rather
+			// than a local branch that may be behind
+			base, _ = git.CurrentBranch(repoRoot)
+			if upstream := git.BranchUpstream(repoRoot, base); upstream != "" {
+				base = upstream
+			}
+		}
+	}
 	if err != nil {
 		return err
 	}
 
+	recordMetadata(repoRoot, worktreePath, branch, base)
+	return nil
+}
+
+// setUpWorktree copies and symlinks the configured files into a new worktree
+// and runs the post_create commands. Failures are warned about and the rest
+// carries on, unless strict is set: then the first failure is returned.
+func setUpWorktree(repoRoot string, cfg *config.Config, worktreePath string, strict bool) error {
+	fail := func(err error) error {
+		if strict {
+			return err
+		}
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		return nil
+	}
+
 	// Copy files
 	if err := worktree.CopyFiles(repoRoot, worktreePath, cfg.CopyFiles); err != nil {
-		fmt.Fprintf(os.Stderr, "Warning: copying files: %v\n", err)
+		if err := fail(fmt.Errorf("copying files: %w", err)); err != nil {
+			return err
+		}
 	}
 	if err := worktree.CopyDirs(repoRoot, worktreePath, cfg.CopyDirs); err != nil {
-		fmt.Fprintf(os.Stderr, "Warning: copying dirs: %v\n", err)
+		if err := fail(fmt.Errorf("copying dirs: %w", err)); err != nil {
+			return err
+		}
 	}
 	if err := worktree.SymlinkFiles(repoRoot, worktreePath, cfg.SymlinkFiles); err != nil {
-		fmt.Fprintf(os.Stderr, "Warning: symlinking files: %v\n", err)
+		if err := fail(fmt.Errorf("symlinking files: %w", err)); err != nil {
+			return err
+		}
 	}
 
 	// Run post_create commands
@@ -84,11 +195,36 @@ func runCreate(cmd *cobra.Command, args []string) error {
 		c.Stdout = os.Stderr
 		c.Stderr = os.Stderr
 		if err := c.Run(); err != nil {
-			fmt.Fprintf(os.Stderr, "Warning: post_create command failed: %v\n", err)
+			if err := fail(fmt.Errorf("post_create command %q failed: %w", command, err)); err != nil {
+				return err
+			}
 		}
 	}
 
-	// Print the path so the shell wrapper can cd to it
-	fmt.Println(worktreePath)
 	return nil
 }
+
+// recordMetadata stores what the worktree was created from. Failures are only
+// warned about — the worktree itself already exists at this point.
+func recordMetadata(repoRoot, worktreePath, branch, base string) {
+	creator := git.UserName(repoRoot)
+	if creator == "" {
+		creator = os.Getenv("USER")
+	}
+
+	err := meta.Update(repoRoot, func(store *meta.Store) error {
+		store.Set(worktreePath, &meta.Entry{
+			Branch:  branch,
+			Base:    base,
+			Created: time.Now().Truncate(time.Second),
+			Creator: creator,
+			Command: "wtt " + strings.Join(os.Args[1:], " "),
+			Note:    createNote,
+			Labels:  createLabels,
+		})
+		return nil
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: saving worktree metadata: %v\n", err)
+	}
+}
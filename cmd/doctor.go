@@ -0,0 +1,376 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/songtov/wtt/internal/config"
+	"github.com/songtov/wtt/internal/directive"
+	"github.com/songtov/wtt/internal/git"
+	"github.com/songtov/wtt/internal/globalconfig"
+	"github.com/songtov/wtt/internal/worktree"
+	"github.com/spf13/cobra"
+)
+
+var (
+	doctorFix      bool
+	doctorAllRepos bool
+)
+
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Check git, the shell integration and the repo's worktrees for problems",
+	Long: `Check that git supports worktrees, that the shell wrapper is loaded and a
+picker is installed, then look for problems in the current repo (or every
+known repo with --all-repos): an invalid .wtt.toml or one naming files that
+don't exist, prunable worktrees and the branches they still hold, worktree
+directories git no longer knows about, and broken symlink_files links.
+
+Each problem comes with a suggested fix; --fix applies the ones wtt can make
+itself, asking first before deleting anything. Exits non-zero while
+problems remain.
+
+See also 'wtt gc', which cleans up stale worktrees and orphaned directories.`,
+	Args: cobra.NoArgs,
+	RunE: runDoctor,
+	// Problems found are not usage errors
+	SilenceUsage: true,
+}
+
+func init() {
+	doctorCmd.Flags().BoolVar(&doctorFix, "fix", false, "Apply the suggested fixes")
+	doctorCmd.Flags().BoolVarP(&doctorAllRepos, "all-repos", "a", false, "Check every known repo, not just the current one")
+}
+
+// minGitMajor and minGitMinor are the oldest git wtt works with: 2.17 added
+// "git worktree remove". Re-adopting orphaned directories also needs "git
+// worktree repair", added in 2.29.
+const (
+	minGitMajor   = 2
+	minGitMinor   = 17
+	adoptGitMinor = 29
+)
+
+// doctor collects the outcome of the checks.
+type doctor struct {
+	problems int
+}
+
+// finding is a problem found by a check.
+type finding struct {
+	problem string
+	// fix describes how to solve the problem.
+	fix string
+	// apply makes the fix with --fix; nil if it has to be done by hand.
+	apply func() error
+	// warning marks advisory findings, which don't make doctor fail.
+	warning bool
+}
+
+func (d *doctor) ok(format string, args ...any) {
+	fmt.Printf("✓ %s\n", fmt.Sprintf(format, args...))
+}
+
+func (d *doctor) report(f finding) {
+	mark := "✗"
+	if f.warning {
+		mark = "!"
+	}
+	fmt.Printf("%s %s\n", mark, f.problem)
+	if f.fix != "" {
+		fmt.Printf("    fix: %s\n", f.fix)
+	}
+	if doctorFix && f.apply != nil {
+		if err := f.apply(); err != nil {
+			fmt.Printf("    fix failed: %v\n", err)
+		} else {
+			fmt.Println("    fixed")
+			return
+		}
+	}
+	if !f.warning {
+		d.problems++
+	}
+}
+
+func runDoctor(_ *cobra.Command, _ []string) error {
+	d := &doctor{}
+	d.checkGit()
+	d.checkShell()
+	d.checkPicker()
+
+	repos, missing, err := knownRepos()
+	if err != nil {
+		return err
+	}
+	d.checkMissingRepos(missing)
+
+	if !doctorAllRepos {
+		repos = nil
+		if root, err := repoRootWithFallback(); err == nil {
+			repos = []string{root}
+		}
+	}
+	for _, repo := range repos {
+		fmt.Printf("\n%s (%s)\n", filepath.Base(repo), repo)
+		d.checkRepo(repo)
+	}
+
+	if d.problems > 0 {
+		if doctorFix {
+			return fmt.Errorf("%d problem(s) remain", d.problems)
+		}
+		return fmt.Errorf("%d problem(s) found; 'wtt doctor --fix' fixes what it can", d.problems)
+	}
+	return nil
+}
+
+func (d *doctor) checkGit() {
+	major, minor, version, err := git.Version()
+	switch {
+	case err != nil:
+		d.report(finding{problem: fmt.Sprintf("cannot determine the git version: %v", err), fix: "install git and make sure it is on $PATH"})
+	case major < minGitMajor || major == minGitMajor && minor < minGitMinor:
+		d.report(finding{
+			problem: fmt.Sprintf("git %s is too old for wtt", version),
+			fix:     fmt.Sprintf("upgrade git to %d.%d or newer", minGitMajor, minGitMinor),
+		})
+	case major == minGitMajor && minor < adoptGitMinor:
+		d.report(finding{
+			problem: fmt.Sprintf("git %s can't re-adopt orphaned worktree directories", version),
+			fix:     fmt.Sprintf("upgrade git to %d.%d or newer to re-adopt them with 'wtt gc'", minGitMajor, adoptGitMinor),
+			warning: true,
+		})
+	default:
+		d.ok("git %s", version)
+	}
+}
+
+func (d *doctor) checkShell() {
+	if directive.Active() {
+		d.ok("shell wrapper loaded")
+		return
+	}
+	sh := filepath.Base(os.Getenv("SHELL"))
+	fix := `add 'eval "$(wtt-bin --init <shell>)"' to your shell's startup file, then open a new shell`
+	switch sh {
+	case "zsh", "bash":
+		fix = fmt.Sprintf(`add 'eval "$(wtt-bin --init %s)"' to ~/.%src, then open a new shell`, sh, sh)
+	case "fish":
+		fix = "add 'wtt-bin --init fish | source' to ~/.config/fish/config.fish, then open a new shell"
+	}
+	d.report(finding{
+		problem: "shell wrapper not loaded: wtt can't change directory or set the session context (ran wtt-bin directly?)",
+		fix:     fix,
+	})
+}
+
+func (d *doctor) checkPicker() {
+	settings, err := globalconfig.LoadSettings()
+	if err != nil {
+		d.report(finding{problem: err.Error(), fix: "fix the syntax of " + settingsPath()})
+	}
+	switch settings.Picker {
+	case "auto", "":
+		for _, bin := range []string{"fzf", "sk"} {
+			if path, err := exec.LookPath(bin); err == nil {
+				d.ok("picker: %s (%s)", bin, path)
+				return
+			}
+		}
+		d.report(finding{
+			problem: "fzf not found; the built-in picker is used",
+			fix:     "install fzf for preview panes and the full set of picker keys",
+			warning: true,
+		})
+	case "builtin", "command":
+		d.ok("picker: %s", settings.Picker)
+	default:
+		if _, err := exec.LookPath(settings.Picker); err != nil {
+			d.report(finding{
+				problem: fmt.Sprintf("picker %q is configured but not installed", settings.Picker),
+				fix:     fmt.Sprintf("install %s or change picker in %s", settings.Picker, settingsPath()),
+			})
+			return
+		}
+		d.ok("picker: %s", settings.Picker)
+	}
+}
+
+func (d *doctor) checkMissingRepos(missing []string) {
+	if len(missing) == 0 {
+		d.ok("known repos all exist")
+		return
+	}
+	for _, r := range missing {
+		repo := r
+		d.report(finding{
+			problem: fmt.Sprintf("known repo %s no longer exists", repo),
+			fix:     "relocate it with 'wtt repo list', or drop it (--fix)",
+			apply:   func() error { return forgetRepo(repo) },
+		})
+	}
+}
+
+func (d *doctor) checkRepo(repoRoot string) {
+	cfg := d.checkConfig(repoRoot)
+
+	worktrees, err := git.ListWorktreesIn(repoRoot)
+	if err != nil {
+		d.report(finding{problem: fmt.Sprintf("listing worktrees: %v", err)})
+		return
+	}
+	d.checkPrunable(repoRoot, worktrees)
+	if cfg != nil {
+		d.checkOrphans(repoRoot, cfg, worktrees)
+		d.checkSymlinks(repoRoot, cfg, worktrees)
+	}
+}
+
+// checkConfig checks that .wtt.toml parses and that the files it copies and
+// symlinks exist. Returns the effective config, or nil if it doesn't load.
+func (d *doctor) checkConfig(repoRoot string) *config.Config {
+	fileCfg, err := config.LoadFile(repoRoot)
+	if err != nil {
+		d.report(finding{problem: err.Error(), fix: "fix the syntax of " + config.Path(repoRoot)})
+		return nil
+	}
+	cfg, err := config.Load(repoRoot, filepath.Base(repoRoot))
+	if err != nil {
+		d.report(finding{problem: err.Error(), fix: "fix the syntax of " + config.Path(repoRoot)})
+		return nil
+	}
+	if fileCfg == nil {
+		d.ok("no .wtt.toml (defaults apply)")
+		return cfg
+	}
+
+	good := true
+	for _, ref := range []struct {
+		key   string
+		paths []string
+	}{
+		{"copy_files", fileCfg.CopyFiles},
+		{"copy_dirs", fileCfg.CopyDirs},
+		{"symlink_files", fileCfg.SymlinkFiles},
+	} {
+		for _, p := range ref.paths {
+			if _, err := os.Stat(filepath.Join(repoRoot, p)); err != nil {
+				good = false
+				d.report(finding{
+					problem: fmt.Sprintf(".wtt.toml: %s entry %q does not exist in the main worktree and is skipped", ref.key, p),
+					fix:     fmt.Sprintf("create %s or remove it from %s", p, ref.key),
+					warning: true,
+				})
+			}
+		}
+	}
+	if good {
+		d.ok(".wtt.toml is valid")
+	}
+	return cfg
+}
+
+// checkPrunable reports worktrees whose directory is gone, which also keep
+// their branch from being checked out anywhere else.
+func (d *doctor) checkPrunable(repoRoot string, worktrees []git.Worktree) {
+	good := true
+	for _, wt := range worktrees {
+		if wt.IsMain {
+			continue
+		}
+		reason := wt.Prunable
+		if _, err := os.Stat(wt.Path); os.IsNotExist(err) && reason == "" {
+			reason = "directory is missing"
+		}
+		if reason == "" {
+			continue
+		}
+		good = false
+		problem := fmt.Sprintf("worktree %s is prunable: %s", wt.Path, reason)
+		if branch := strings.TrimPrefix(wt.Branch, "refs/heads/"); branch != "" {
+			problem += fmt.Sprintf("; branch %q stays checked out there and can't be used by another worktree", branch)
+		}
+		f := finding{
+			problem: problem,
+			fix:     "git worktree prune (--fix)",
+			apply:   func() error { return git.Prune(repoRoot) },
+		}
+		if wt.Locked {
+			f.fix = fmt.Sprintf("it is locked: git worktree unlock %s, then git worktree prune", wt.Path)
+			f.apply = nil
+		}
+		d.report(f)
+	}
+	if good {
+		d.ok("no prunable worktrees")
+	}
+}
+
+// checkOrphans reports directories in worktree_dir that git no longer
+// knows as worktrees.
+func (d *doctor) checkOrphans(repoRoot string, cfg *config.Config, worktrees []git.Worktree) {
+	orphans, err := orphanedDirs(repoRoot, cfg, worktrees)
+	if err != nil {
+		d.report(finding{problem: err.Error(), warning: true})
+		return
+	}
+	if len(orphans) == 0 {
+		d.ok("no orphaned worktree directories")
+		return
+	}
+	for _, dir := range orphans {
+		path := dir
+		d.report(finding{
+			problem: fmt.Sprintf("%s is in worktree_dir but not a worktree git knows about", path),
+			fix:     "delete or re-adopt it with 'wtt gc' (--fix asks which)",
+			apply: func() error {
+				_, kept, err := resolveOrphan(repoRoot, path, "")
+				if err == nil && kept {
+					err = fmt.Errorf("kept %s", path)
+				}
+				return err
+			},
+		})
+	}
+}
+
+// checkSymlinks reports symlink_files links in the worktrees whose target
+// has gone.
+func (d *doctor) checkSymlinks(repoRoot string, cfg *config.Config, worktrees []git.Worktree) {
+	good := true
+	for _, wt := range worktrees {
+		if wt.IsMain {
+			continue
+		}
+		for _, f := range cfg.SymlinkFiles {
+			link := filepath.Join(wt.Path, f)
+			info, err := os.Lstat(link)
+			if err != nil || info.Mode()&os.ModeSymlink == 0 {
+				continue
+			}
+			if _, err := os.Stat(link); err == nil {
+				continue
+			}
+			good = false
+			file, path := f, wt.Path
+			src := filepath.Join(repoRoot, f)
+			fd := finding{
+				problem: fmt.Sprintf("broken symlink %s", link),
+				fix:     fmt.Sprintf("%s is gone from the main worktree; delete the link (--fix)", f),
+				apply:   func() error { return os.Remove(link) },
+			}
+			if _, err := os.Stat(src); err == nil {
+				fd.fix = "re-create the link (--fix)"
+				fd.apply = func() error { return worktree.SymlinkFiles(repoRoot, path, []string{file}) }
+			}
+			d.report(fd)
+		}
+	}
+	if good && len(cfg.SymlinkFiles) > 0 {
+		d.ok("symlink_files links intact")
+	}
+}
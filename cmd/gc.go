@@ -0,0 +1,313 @@
+package cmd
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/songtov/wtt/internal/config"
+	"github.com/songtov/wtt/internal/git"
+	"github.com/songtov/wtt/internal/meta"
+	"github.com/songtov/wtt/internal/worktree"
+	"github.com/spf13/cobra"
+)
+
+var (
+	gcDryRun   bool
+	gcAllRepos bool
+)
+
+var gcCmd = &cobra.Command{
+	Use:   "gc",
+	Short: "Clean up stale worktrees and orphaned worktree directories",
+	Long: `Reconcile the worktree directories on disk with the worktrees git knows about.
+
+Worktrees git still lists but whose directory is gone (or no longer links
+back to the repo) are pruned with 'git worktree prune', which also frees
+their branches for other worktrees. Directories in worktree_dir that git no
+longer knows — after a crash or a manual rm of .git — are shown with their
+size, and for each you can delete it, re-adopt it as the worktree of a
+branch keeping its files, or keep it. Reports the disk space reclaimed.`,
+	Args: cobra.NoArgs,
+	RunE: runGC,
+}
+
+func init() {
+	gcCmd.Flags().BoolVarP(&gcDryRun, "dry-run", "n", false, "Only show what would be cleaned up")
+	gcCmd.Flags().BoolVarP(&gcAllRepos, "all-repos", "a", false, "Clean up every known repo, not just the current one")
+}
+
+func runGC(_ *cobra.Command, _ []string) error {
+	var repos []string
+	if gcAllRepos {
+		var err error
+		if repos, err = canonicalRepos(); err != nil {
+			return err
+		}
+	} else {
+		repoRoot, err := repoRootWithFallback()
+		if err != nil {
+			return err
+		}
+		autoRegisterRepo(repoRoot)
+		repos = []string{repoRoot}
+	}
+
+	var reclaimed int64
+	clean := true
+	for _, repo := range repos {
+		n, found, err := gcRepo(repo)
+		if err != nil {
+			return fmt.Errorf("%s: %w", filepath.Base(repo), err)
+		}
+		reclaimed += n
+		clean = clean && !found
+	}
+
+	switch {
+	case clean:
+		fmt.Fprintln(os.Stderr, "Nothing to clean up.")
+	case !gcDryRun:
+		fmt.Fprintf(os.Stderr, "Reclaimed %s\n", humanizeBytes(reclaimed))
+	}
+	return nil
+}
+
+// gcRepo prunes the repo's stale worktrees and resolves its orphaned
+// directories. Returns the bytes reclaimed and whether there was anything
+// to clean up.
+func gcRepo(repoRoot string) (int64, bool, error) {
+	cfg, err := config.Load(repoRoot, filepath.Base(repoRoot))
+	if err != nil {
+		return 0, false, fmt.Errorf("loading config: %w", err)
+	}
+	worktrees, err := git.ListWorktreesIn(repoRoot)
+	if err != nil {
+		return 0, false, fmt.Errorf("listing worktrees: %w", err)
+	}
+	prefix := ""
+	if gcAllRepos {
+		prefix = filepath.Base(repoRoot) + ": "
+	}
+
+	// Stale entries whose directory still exists turn into orphans once
+	// pruned; remember their branch to suggest when re-adopting them
+	branchHint := map[string]string{}
+	var stale []string
+	for _, wt := range worktrees {
+		if wt.IsMain {
+			continue
+		}
+		reason := wt.Prunable
+		if _, err := os.Stat(wt.Path); os.IsNotExist(err) && reason == "" {
+			reason = "directory is missing"
+		}
+		if reason == "" {
+			continue
+		}
+		if wt.Locked {
+			fmt.Fprintf(os.Stderr, "%sSkipping locked stale worktree %s (git worktree unlock it first)\n", prefix, wt.Path)
+			continue
+		}
+		fmt.Fprintf(os.Stderr, "%sStale worktree %s: %s\n", prefix, wt.Path, reason)
+		stale = append(stale, wt.Path)
+		if isDir(wt.Path) {
+			branchHint[wt.Path] = strings.TrimPrefix(wt.Branch, "refs/heads/")
+		}
+	}
+
+	if len(stale) > 0 && !gcDryRun {
+		if err := git.Prune(repoRoot); err != nil {
+			return 0, true, err
+		}
+		_ = meta.Update(repoRoot, func(store *meta.Store) error {
+			for _, p := range stale {
+				store.Delete(p)
+			}
+			return nil
+		})
+		fmt.Fprintf(os.Stderr, "%sPruned %d stale worktree(s)\n", prefix, len(stale))
+		if worktrees, err = git.ListWorktreesIn(repoRoot); err != nil {
+			return 0, true, fmt.Errorf("listing worktrees: %w", err)
+		}
+	}
+
+	orphans, err := orphanedDirs(repoRoot, cfg, worktrees)
+	if err != nil {
+		return 0, true, err
+	}
+	if gcDryRun {
+		// Pruning would orphan the stale entries whose directory exists
+		for p := range branchHint {
+			orphans = append(orphans, p)
+		}
+	}
+	var reclaimed int64
+	for _, dir := range orphans {
+		if gcDryRun {
+			fmt.Fprintf(os.Stderr, "%sOrphaned directory %s (%s)\n", prefix, dir, humanizeBytes(dirSize(dir)))
+			continue
+		}
+		n, _, err := resolveOrphan(repoRoot, dir, branchHint[dir])
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		}
+		reclaimed += n
+	}
+	return reclaimed, len(stale) > 0 || len(orphans) > 0, nil
+}
+
+// resolveOrphan asks whether to delete, re-adopt or keep the orphaned
+// worktree directory dir. branch, if known, is suggested for re-adopting.
+// Returns the bytes reclaimed and whether the directory was kept.
+func resolveOrphan(repoRoot, dir, branch string) (int64, bool, error) {
+	size := dirSize(dir)
+	answer := strings.ToLower(ask(fmt.Sprintf("Orphaned directory %s (%s). [d]elete, [a]dopt or [k]eep? [k] ", dir, humanizeBytes(size))))
+	switch answer {
+	case "d", "delete":
+		if err := os.RemoveAll(dir); err != nil {
+			return 0, false, fmt.Errorf("deleting %s: %w", dir, err)
+		}
+		fmt.Fprintf(os.Stderr, "Deleted %s\n", dir)
+		return size, false, nil
+	case "a", "adopt":
+		if branch == "" {
+			branch = guessBranch(repoRoot, dir)
+		}
+		if b := ask(fmt.Sprintf("Branch [%s]: ", branch)); b != "" {
+			branch = b
+		}
+		if err := git.ValidateBranchName(branch); err != nil {
+			return 0, true, err
+		}
+		if err := worktree.Adopt(repoRoot, dir, branch); err != nil {
+			return 0, true, fmt.Errorf("adopting %s: %w", dir, err)
+		}
+		fmt.Fprintf(os.Stderr, "Adopted %s as the worktree of %q\n", dir, branch)
+		return 0, false, nil
+	}
+	return 0, true, nil
+}
+
+// guessBranch returns the local branch whose worktree directory would be
+// named like dir, falling back to the directory name itself.
+func guessBranch(repoRoot, dir string) string {
+	name := filepath.Base(dir)
+	if branches, err := git.ListRefs(repoRoot, "refs/heads"); err == nil {
+		for _, b := range branches {
+			if git.BranchToPath(b) == name {
+				return b
+			}
+		}
+	}
+	return name
+}
+
+// orphanedDirs returns the directories in the repo's worktree_dir that are
+// neither a worktree git knows about, nor a repository of their own, nor a
+// live worktree of another repo.
+func orphanedDirs(repoRoot string, cfg *config.Config, worktrees []git.Worktree) ([]string, error) {
+	base := worktreeBaseDir(repoRoot, cfg)
+	// A worktree_dir holding the repo itself (e.g. "..") is shared with
+	// unrelated directories, which must not be mistaken for orphans
+	if rel, err := filepath.Rel(base, repoRoot); err == nil && !strings.HasPrefix(rel, "..") {
+		return nil, nil
+	}
+	entries, err := os.ReadDir(base)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading worktree_dir: %w", err)
+	}
+
+	known := map[string]bool{}
+	for _, wt := range worktrees {
+		known[wt.Path] = true
+		if resolved, err := filepath.EvalSymlinks(wt.Path); err == nil {
+			known[resolved] = true
+		}
+	}
+	var commonDirs []string
+	if loc, err := git.Locate(repoRoot); err == nil {
+		commonDirs = append(commonDirs, loc.CommonDir)
+		if resolved, err := filepath.EvalSymlinks(loc.CommonDir); err == nil && resolved != loc.CommonDir {
+			commonDirs = append(commonDirs, resolved)
+		}
+	}
+	var orphans []string
+	for _, e := range entries {
+		if !e.IsDir() {
+			continue
+		}
+		path := filepath.Join(base, e.Name())
+		if resolved, err := filepath.EvalSymlinks(path); err == nil && known[resolved] || known[path] {
+			continue
+		}
+		if !orphanedGitFile(filepath.Join(path, ".git"), commonDirs) {
+			continue
+		}
+		orphans = append(orphans, path)
+	}
+	return orphans, nil
+}
+
+// orphanedGitFile reports whether the .git entry dotGit leaves its directory
+// an orphan of the repo whose git dir is one of commonDirs: there is no .git
+// at all, or it is a file pointing into the repo's git dir at administrative
+// files that are gone. A .git directory is a repository of its own, and a
+// .git file with a live git dir a worktree — possibly of another repo sharing
+// worktree_dir.
+func orphanedGitFile(dotGit string, commonDirs []string) bool {
+	info, err := os.Lstat(dotGit)
+	if os.IsNotExist(err) {
+		return true
+	}
+	if err != nil || info.IsDir() {
+		return false
+	}
+	target, err := git.GitFileTarget(dotGit)
+	if err != nil {
+		return false
+	}
+	if _, err := os.Stat(target); err == nil {
+		return false
+	}
+	for _, common := range commonDirs {
+		if rel, err := filepath.Rel(common, target); err == nil && !strings.HasPrefix(rel, "..") {
+			return true
+		}
+	}
+	return false
+}
+
+// dirSize returns the total size of the files under dir.
+func dirSize(dir string) int64 {
+	var size int64
+	_ = filepath.WalkDir(dir, func(_ string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if info, err := d.Info(); err == nil && !d.IsDir() {
+			size += info.Size()
+		}
+		return nil
+	})
+	return size
+}
+
+// humanizeBytes formats n bytes, e.g. "1.2 GB".
+func humanizeBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(n)/float64(div), "KMGTPE"[exp])
+}
@@ -25,6 +25,22 @@ symlink_files = [".claude/settings.local.json"]
 
 # Commands to run after creating a worktree
 # post_create = []
+
+# How "wtt sync" updates branches onto their base: "rebase" or "merge"
+# sync_strategy = "rebase"
+
+# Create the worktree when "wtt <branch>" finds none (asks before creating a new branch)
+# create_on_miss = false
+
+# Keep the current subdirectory when switching worktrees
+# keep_subdir = false
+
+# Files sourced by the shell after navigating to a worktree (relative to it)
+# source_files = []
+
+# Environment variables exported after navigating; {path}, {branch} and {repo} are expanded
+# [env]
+# COMPOSE_PROJECT_NAME = "{repo}-{branch}"
 `
 
 var initForce bool
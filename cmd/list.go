@@ -2,21 +2,57 @@ package cmd
 
 import (
 	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
 
-	"github.com/songtov/wtt/internal/fzf"
+	"github.com/songtov/wtt/internal/config"
 	"github.com/songtov/wtt/internal/git"
+	"github.com/songtov/wtt/internal/globalconfig"
+	"github.com/songtov/wtt/internal/picker"
 	"github.com/spf13/cobra"
 )
 
+var (
+	listSort       string
+	listAllRepos   bool
+	listSwitchRepo bool
+)
+
+func init() {
+	listCmd.Flags().StringVar(&listSort, "sort", "frecency", "Picker order: frecency, name, recent or created")
+	listCmd.Flags().BoolVarP(&listAllRepos, "all-repos", "a", false, "List the worktrees of every known repo")
+	listCmd.Flags().BoolVar(&listSwitchRepo, "switch-repo", false, "With --all-repos, also make the chosen worktree's repo the active context")
+	listCmd.Flags().BoolVar(&keepSubdir, "keep-subdir", false, keepSubdirUsage)
+	_ = listCmd.RegisterFlagCompletionFunc("sort", cobra.FixedCompletions(sortModes, cobra.ShellCompDirectiveNoFileComp))
+}
+
 var listCmd = &cobra.Command{
 	Use:   "list",
 	Short: "List and navigate worktrees",
-	Long:  `List all worktrees and interactively select one to navigate to (requires fzf).`,
-	Args:  cobra.NoArgs,
-	RunE:  runList,
+	Long: `List all worktrees and interactively select one to navigate to (requires fzf).
+
+Inside the fzf picker:
+  enter   navigate to the worktree
+  ctrl-d  remove the worktree (asks for confirmation)
+  ctrl-n  create a worktree for the typed query
+  ctrl-o  open the worktree in $VISUAL / $EDITOR
+  ctrl-y  copy the worktree path to the clipboard
+
+With --all-repos the picker lists the worktrees of every known repo, grouped
+by repo name (the current repo first), so you can jump straight into another
+repo's worktree. Add --switch-repo to also make that repo the active context.`,
+	Args: cobra.NoArgs,
+	RunE: runList,
 }
 
 func runList(_ *cobra.Command, _ []string) error {
+	if listAllRepos {
+		return runListAllRepos()
+	}
 	repoRoot, err := repoRootWithFallback()
 	if err != nil {
 		return err
@@ -27,16 +63,194 @@ func runList(_ *cobra.Command, _ []string) error {
 	if err != nil {
 		return fmt.Errorf("listing worktrees: %w", err)
 	}
+	if err := sortWorktrees(repoRoot, worktrees, listSort); err != nil {
+		return err
+	}
 
-	path, err := fzf.Select(worktrees)
+	opts := pickerOptions(repoRoot)
+	opts.Actions = true
+	res, err := picker.Pick(worktrees, opts)
 	if err != nil {
 		return err
 	}
-	if path == "" {
+	if res == nil {
 		return nil // user cancelled
 	}
 
-	// Print path so the shell wrapper can cd to it
-	fmt.Println(path)
+	switch res.Action {
+	case picker.ActionCreate:
+		branch := strings.TrimSpace(res.Query)
+		if branch == "" {
+			return fmt.Errorf("type a branch name before pressing ctrl-n")
+		}
+		if err := git.ValidateBranchName(branch); err != nil {
+			return err
+		}
+		cfg, err := config.Load(repoRoot, filepath.Base(repoRoot))
+		if err != nil {
+			return fmt.Errorf("loading config: %w", err)
+		}
+		path, err := createWorktree(repoRoot, cfg, branch, "")
+		if err != nil {
+			return err
+		}
+		return navigate(repoRoot, path)
+	case picker.ActionRemove:
+		if res.Worktree.IsMain {
+			return fmt.Errorf("cannot remove the main worktree")
+		}
+		branch := strings.TrimPrefix(res.Worktree.Branch, "refs/heads/")
+		return removeWorktree(repoRoot, res.Worktree.Path, branch, false)
+	case picker.ActionEdit:
+		return openInEditor(res.Worktree.Path)
+	case picker.ActionCopy:
+		if err := copyToClipboard(res.Worktree.Path); err != nil {
+			return err
+		}
+		fmt.Fprintf(os.Stderr, "Copied %s\n", res.Worktree.Path)
+	default:
+		return navigate(repoRoot, res.Worktree.Path)
+	}
 	return nil
 }
+
+// runListAllRepos implements "wtt list --all-repos": one picker over the
+// worktrees of every known repo.
+func runListAllRepos() error {
+	if !slices.Contains(sortModes, listSort) {
+		return fmt.Errorf("invalid sort %q (want one of: %s)", listSort, strings.Join(sortModes, ", "))
+	}
+	repos, err := canonicalRepos()
+	if err != nil {
+		return err
+	}
+	// The repo you're in (or the active context) comes first
+	if current, err := repoRootWithFallback(); err == nil {
+		autoRegisterRepo(current)
+		if i := slices.Index(repos, current); i > 0 {
+			repos = append([]string{current}, slices.Delete(repos, i, i+1)...)
+		} else if i < 0 {
+			repos = append([]string{current}, repos...)
+		}
+	}
+	if len(repos) == 0 {
+		return fmt.Errorf("no repos registered yet; run wtt commands inside a git repo first")
+	}
+
+	groups := make([][]git.Worktree, len(repos))
+	details := make([]map[string]string, len(repos))
+	errs := make([]error, len(repos))
+	var wg sync.WaitGroup
+	for i, repo := range repos {
+		wg.Add(1)
+		go func(i int, repo string) {
+			defer wg.Done()
+			worktrees, err := git.ListWorktreesIn(repo)
+			if err == nil {
+				err = sortWorktrees(repo, worktrees, listSort)
+			}
+			groups[i], details[i], errs[i] = worktrees, worktreeDetails(repo), err
+		}(i, repo)
+	}
+	wg.Wait()
+
+	opts := pickerOptions(repos[0])
+	opts.Actions = true
+	opts.Details = map[string]string{}
+	opts.Repos = map[string]string{}
+	repoOf := map[string]string{}
+	var worktrees []git.Worktree
+	for i, repo := range repos {
+		if errs[i] != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %s: %v\n", filepath.Base(repo), errs[i])
+			continue
+		}
+		for _, wt := range groups[i] {
+			worktrees = append(worktrees, wt)
+			repoOf[wt.Path] = repo
+			opts.Repos[wt.Path] = filepath.Base(repo)
+			if d := details[i][wt.Path]; d != "" {
+				opts.Details[wt.Path] = d
+			}
+		}
+	}
+
+	res, err := picker.Pick(worktrees, opts)
+	if err != nil {
+		return err
+	}
+	if res == nil {
+		return nil // user cancelled
+	}
+
+	if res.Action == picker.ActionCreate {
+		return fmt.Errorf("ctrl-n creates a worktree in one repo; run 'wtt list' without --all-repos in that repo")
+	}
+	repoRoot := repoOf[res.Worktree.Path]
+	switch res.Action {
+	case picker.ActionRemove:
+		if res.Worktree.IsMain {
+			return fmt.Errorf("cannot remove the main worktree")
+		}
+		branch := strings.TrimPrefix(res.Worktree.Branch, "refs/heads/")
+		return removeWorktree(repoRoot, res.Worktree.Path, branch, false)
+	case picker.ActionEdit:
+		return openInEditor(res.Worktree.Path)
+	case picker.ActionCopy:
+		if err := copyToClipboard(res.Worktree.Path); err != nil {
+			return err
+		}
+		fmt.Fprintf(os.Stderr, "Copied %s\n", res.Worktree.Path)
+		return nil
+	}
+
+	if listSwitchRepo {
+		if current, _ := globalconfig.GetCurrentRepo(); current != repoRoot {
+			if err := useRepo(repoRoot); err != nil {
+				return err
+			}
+		}
+	}
+	return navigate(repoRoot, res.Worktree.Path)
+}
+
+// openInEditor opens path in $VISUAL or $EDITOR. The editor is attached to
+// the terminal directly, so it works even when stdout is redirected.
+func openInEditor(path string) error {
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	if editor == "" {
+		return fmt.Errorf("set $VISUAL or $EDITOR to open worktrees in an editor")
+	}
+
+	// Run through sh so editors with arguments (e.g. "code -w") work
+	c := exec.Command("sh", "-c", editor+` "$1"`, "sh", path)
+	c.Dir = path
+	c.Stdin, c.Stdout, c.Stderr = os.Stdin, os.Stderr, os.Stderr
+	if tty, err := os.OpenFile("/dev/tty", os.O_RDWR, 0); err == nil {
+		defer tty.Close()
+		c.Stdin, c.Stdout, c.Stderr = tty, tty, tty
+	}
+	return c.Run()
+}
+
+// copyToClipboard copies text using the first available clipboard tool.
+func copyToClipboard(text string) error {
+	tools := [][]string{
+		{"pbcopy"},
+		{"wl-copy"},
+		{"xclip", "-selection", "clipboard"},
+		{"xsel", "--clipboard", "--input"},
+	}
+	for _, tool := range tools {
+		if _, err := exec.LookPath(tool[0]); err != nil {
+			continue
+		}
+		c := exec.Command(tool[0], tool[1:]...)
+		c.Stdin = strings.NewReader(text)
+		return c.Run()
+	}
+	return fmt.Errorf("no clipboard tool found (install pbcopy, wl-copy, xclip or xsel)")
+}
@@ -0,0 +1,87 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/songtov/wtt/internal/git"
+	"github.com/songtov/wtt/internal/meta"
+	"github.com/spf13/cobra"
+)
+
+var (
+	noteLabels []string
+	noteClear  bool
+)
+
+var noteCmd = &cobra.Command{
+	Use:   "note <branch> [text]",
+	Short: "Show or edit a worktree's note and labels",
+	Long: `Attach a free-text note and labels to a worktree. With only a branch,
+prints the current note. Notes are shown in pickers and 'wtt status'.`,
+	Args:              cobra.RangeArgs(1, 2),
+	ValidArgsFunction: completeWorktrees,
+	RunE:              runNote,
+}
+
+func init() {
+	noteCmd.Flags().StringSliceVarP(&noteLabels, "label", "l", nil, "Replace the worktree's labels (comma-separated or repeatable)")
+	noteCmd.Flags().BoolVar(&noteClear, "clear", false, "Remove the note and labels")
+}
+
+func runNote(cmd *cobra.Command, args []string) error {
+	repoRoot, err := repoRootWithFallback()
+	if err != nil {
+		return err
+	}
+	autoRegisterRepo(repoRoot)
+
+	worktrees, err := git.ListWorktreesIn(repoRoot)
+	if err != nil {
+		return fmt.Errorf("listing worktrees: %w", err)
+	}
+	branch := args[0]
+	wt := findWorktree(worktrees, branch)
+	if wt == nil {
+		return fmt.Errorf("no worktree found for branch %q", branch)
+	}
+
+	editing := len(args) == 2 || noteClear || cmd.Flags().Changed("label")
+	if !editing {
+		store, err := meta.Load(repoRoot)
+		if err != nil {
+			return fmt.Errorf("loading metadata: %w", err)
+		}
+		if entry := store.Get(wt.Path); entry != nil && entry.Summary() != "" {
+			fmt.Println(entry.Summary())
+		}
+		return nil
+	}
+
+	err = meta.Update(repoRoot, func(store *meta.Store) error {
+		entry := store.Get(wt.Path)
+		if entry == nil {
+			// Worktree created outside wtt (or before metadata existed)
+			entry = &meta.Entry{Branch: strings.TrimPrefix(wt.Branch, "refs/heads/")}
+		}
+		if noteClear {
+			entry.Note = ""
+			entry.Labels = nil
+		}
+		if len(args) == 2 {
+			entry.Note = args[1]
+		}
+		if cmd.Flags().Changed("label") {
+			entry.Labels = noteLabels
+		}
+		store.Set(wt.Path, entry)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("saving metadata: %w", err)
+	}
+
+	fmt.Fprintf(os.Stderr, "Updated note for %q\n", branch)
+	return nil
+}
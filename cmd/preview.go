@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/songtov/wtt/internal/git"
+	"github.com/songtov/wtt/internal/meta"
+	"github.com/spf13/cobra"
+)
+
+var previewCmd = &cobra.Command{
+	Use:    "preview <path>",
+	Short:  "Render a worktree summary for picker preview panes",
+	Long:   `Print a worktree's path, branch, ahead/behind counts, git status, recent commits and note. Used by the fzf preview pane in pickers.`,
+	Args:   cobra.ExactArgs(1),
+	Hidden: true, // called back by pickers
+	RunE:   runPreview,
+}
+
+func runPreview(_ *cobra.Command, args []string) error {
+	path := args[0]
+
+	const (
+		bold  = "\033[1m"
+		dim   = "\033[2m"
+		reset = "\033[0m"
+	)
+
+	branch, err := git.CurrentBranch(path)
+	if err != nil {
+		fmt.Printf("%s%s%s\n\n(worktree directory is missing or not a git checkout)\n", bold, path, reset)
+		return nil
+	}
+	fmt.Printf("%s%s%s\n%s%s%s\n", bold, branch, reset, dim, path, reset)
+
+	var entry *meta.Entry
+	if repoRoot, err := git.MainRepoRootOf(path); err == nil {
+		if store, err := meta.Load(repoRoot); err == nil {
+			entry = store.Get(path)
+		}
+	}
+
+	// Compare against the upstream, or the recorded base when there is none
+	base := git.Upstream(path)
+	if base == "" && entry != nil {
+		base = entry.Base
+	}
+	if base != "" {
+		if ahead, behind, err := git.AheadBehind(path, base); err == nil {
+			fmt.Printf("↑%d ↓%d vs %s\n", ahead, behind, base)
+		}
+	}
+
+	if entry != nil && entry.Summary() != "" {
+		fmt.Printf("\n%sNote:%s %s\n", bold, reset, entry.Summary())
+	}
+
+	if status, err := git.StatusShort(path); err == nil {
+		fmt.Printf("\n%sStatus%s\n", bold, reset)
+		if strings.TrimSpace(status) == "" {
+			fmt.Println(dim + "clean" + reset)
+		} else {
+			fmt.Print(status)
+		}
+	}
+
+	if log, err := git.RecentCommits(path, 10); err == nil {
+		fmt.Printf("\n%sRecent commits%s\n%s", bold, reset, log)
+	}
+	return nil
+}
@@ -0,0 +1,60 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/songtov/wtt/internal/git"
+	"github.com/songtov/wtt/internal/history"
+	"github.com/songtov/wtt/internal/picker"
+	"github.com/spf13/cobra"
+)
+
+var recentCmd = &cobra.Command{
+	Use:   "recent",
+	Short: "Pick a recently visited worktree",
+	Long:  `List worktrees you navigated to with wtt, most recent first, and interactively select one to navigate to.`,
+	Args:  cobra.NoArgs,
+	RunE:  runRecent,
+}
+
+func runRecent(_ *cobra.Command, _ []string) error {
+	repoRoot, err := repoRootWithFallback()
+	if err != nil {
+		return err
+	}
+	autoRegisterRepo(repoRoot)
+
+	visits, err := history.Load(repoRoot)
+	if err != nil {
+		return fmt.Errorf("loading history: %w", err)
+	}
+	worktrees, err := git.ListWorktreesIn(repoRoot)
+	if err != nil {
+		return fmt.Errorf("listing worktrees: %w", err)
+	}
+	byPath := map[string]git.Worktree{}
+	for _, wt := range worktrees {
+		byPath[wt.Path] = wt
+	}
+
+	// Skip the current worktree and any that have since been removed
+	cur := currentWorktree(repoRoot)
+	var recent []git.Worktree
+	for _, p := range history.Recent(visits) {
+		if wt, ok := byPath[p]; ok && p != cur {
+			recent = append(recent, wt)
+		}
+	}
+	if len(recent) == 0 {
+		return fmt.Errorf("no recently visited worktrees")
+	}
+
+	path, err := picker.Select(recent, pickerOptions(repoRoot))
+	if err != nil {
+		return err
+	}
+	if path == "" {
+		return nil // user cancelled
+	}
+	return navigate(repoRoot, path)
+}
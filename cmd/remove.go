@@ -1,33 +1,51 @@
 package cmd
 
 import (
-	"bufio"
 	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
 
-	"github.com/songtov/wtt/internal/fzf"
 	"github.com/songtov/wtt/internal/git"
+	"github.com/songtov/wtt/internal/meta"
+	"github.com/songtov/wtt/internal/picker"
 	"github.com/songtov/wtt/internal/worktree"
 	"github.com/spf13/cobra"
 )
 
-var forceRemove bool
+var (
+	forceRemove bool
+	removeSort  string
+	removeInWS  string
+)
 
 var removeCmd = &cobra.Command{
 	Use:   "remove [branch]",
 	Short: "Remove a worktree",
-	Long:  `Remove the git worktree associated with the given branch name. If no branch is given, opens an interactive picker.`,
-	Args:  cobra.MaximumNArgs(1),
-	RunE:  runRemove,
+	Long: `Remove the git worktree associated with the given branch name. If no branch is given, opens an interactive picker where tab marks several worktrees.
+
+With --workspace, removes the branch's worktrees in every repo of the workspace.`,
+	Args:              cobra.MaximumNArgs(1),
+	ValidArgsFunction: completeRemovableWorktrees,
+	RunE:              runRemove,
 }
 
 func init() {
 	removeCmd.Flags().BoolVarP(&forceRemove, "force", "f", false, "Skip confirmation prompt")
+	removeCmd.Flags().StringVar(&removeSort, "sort", "frecency", "Picker order: frecency, name, recent or created")
+	removeCmd.Flags().StringVarP(&removeInWS, "workspace", "w", "", workspaceUsage)
+	_ = removeCmd.RegisterFlagCompletionFunc("sort", cobra.FixedCompletions(sortModes, cobra.ShellCompDirectiveNoFileComp))
+	_ = removeCmd.RegisterFlagCompletionFunc("workspace", completeWorkspaces)
 }
 
 func runRemove(_ *cobra.Command, args []string) error {
+	if removeInWS != "" {
+		if len(args) == 0 {
+			return fmt.Errorf("usage: wtt remove --workspace <name> <branch>")
+		}
+		return removeWorkspace(removeInWS, args[0])
+	}
+
 	repoRoot, err := repoRootWithFallback()
 	if err != nil {
 		return err
@@ -42,55 +60,72 @@ func runRemove(_ *cobra.Command, args []string) error {
 	// Skip the main worktree (first entry) from removal candidates
 	removable := worktrees[1:]
 
-	var targetPath, branch string
-
 	if len(args) == 0 {
-		// No branch given: open interactive picker
+		// No branch given: open interactive picker (tab marks several)
 		if len(removable) == 0 {
 			return fmt.Errorf("no worktrees to remove")
 		}
-		selected, err := fzf.SelectWorktree(removable)
+		if err := sortWorktrees(repoRoot, removable, removeSort); err != nil {
+			return err
+		}
+		selected, err := picker.SelectWorktrees(removable, pickerOptions(repoRoot))
 		if err != nil {
 			return err
 		}
-		if selected == nil {
+		if len(selected) == 0 {
 			return nil // user cancelled
 		}
-		targetPath = selected.Path
-		branch = strings.TrimPrefix(selected.Branch, "refs/heads/")
-	} else {
-		branch = args[0]
-		// Check if user is trying to remove the main worktree
-		main := worktrees[0]
-		if main.Branch == branch || main.Branch == "refs/heads/"+branch {
-			return fmt.Errorf("cannot remove the main worktree")
-		}
-		for _, wt := range removable {
-			if wt.Branch == branch || wt.Branch == "refs/heads/"+branch {
-				targetPath = wt.Path
-				break
+		for _, wt := range selected {
+			branch := strings.TrimPrefix(wt.Branch, "refs/heads/")
+			if err := removeWorktree(repoRoot, wt.Path, branch, forceRemove); err != nil {
+				return err
 			}
 		}
-		if targetPath == "" {
-			return fmt.Errorf("no worktree found for branch %q", branch)
-		}
+		return nil
 	}
 
-	if !forceRemove {
-		fmt.Fprintf(os.Stderr, "Remove worktree at %s? [y/N] ", targetPath)
-		scanner := bufio.NewScanner(os.Stdin)
-		scanner.Scan()
-		answer := strings.TrimSpace(strings.ToLower(scanner.Text()))
-		if answer != "y" && answer != "yes" {
-			fmt.Fprintln(os.Stderr, "Aborted.")
-			return nil
+	branch := args[0]
+	// Check if user is trying to remove the main worktree
+	main := worktrees[0]
+	if main.Branch == branch || main.Branch == "refs/heads/"+branch {
+		return fmt.Errorf("cannot remove the main worktree")
+	}
+	var targetPath string
+	for _, wt := range removable {
+		if wt.Branch == branch || wt.Branch == "refs/heads/"+branch {
+			targetPath = wt.Path
+			break
 		}
 	}
+	if targetPath == "" {
+		return fmt.Errorf("no worktree found for branch %q", branch)
+	}
+
+	return removeWorktree(repoRoot, targetPath, branch, forceRemove)
+}
 
-	if err := worktree.Remove(repoRoot, targetPath, forceRemove); err != nil {
+// removeWorktree removes the worktree at targetPath after confirming (unless
+// force), forgets its metadata and deletes its parent directory if that is
+// left empty.
+func removeWorktree(repoRoot, targetPath, branch string, force bool) error {
+	if !force && !confirm(fmt.Sprintf("Remove worktree at %s? [y/N] ", targetPath)) {
+		fmt.Fprintln(os.Stderr, "Aborted.")
+		return nil
+	}
+	return deleteWorktree(repoRoot, targetPath, branch, force)
+}
+
+// deleteWorktree is removeWorktree without the confirmation.
+func deleteWorktree(repoRoot, targetPath, branch string, force bool) error {
+	if err := worktree.Remove(repoRoot, targetPath, force); err != nil {
 		return err
 	}
 
+	_ = meta.Update(repoRoot, func(store *meta.Store) error {
+		store.Delete(targetPath)
+		return nil
+	})
+
 	// Clean up empty parent directory
 	parent := filepath.Dir(targetPath)
 	entries, err := os.ReadDir(parent)
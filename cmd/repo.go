@@ -2,6 +2,10 @@ package cmd
 
 import (
 	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
 
 	"github.com/songtov/wtt/internal/git"
 	"github.com/songtov/wtt/internal/globalconfig"
@@ -9,35 +13,137 @@ import (
 )
 
 var repoCmd = &cobra.Command{
-	Use:   "repo",
+	Use:   "repo [-]",
 	Short: "Manage repository contexts",
 	Long: `Switch between repositories and manage the active repo context.
 All subsequent wtt commands (create, list, remove) will operate on the
-active repository even when run from outside it — just like kubens for kubectl.`,
+active repository even when run from outside it — just like kubens for kubectl.
+
+"wtt repo -" switches back to the previous context.`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runRepo,
+}
+
+func init() {
+	repoCmd.Flags().BoolVarP(&repoUseGlobal, "global", "g", false, "With -, switch back for all shell sessions")
+}
+
+func runRepo(cmd *cobra.Command, args []string) error {
+	if len(args) == 0 {
+		return cmd.Help()
+	}
+	if args[0] != "-" {
+		return fmt.Errorf("unknown command %q for \"wtt repo\"", args[0])
+	}
+	return runRepoBack()
 }
 
 // canonicalRepos loads the known repos, normalizes each to its main repo root,
-// deduplicates, and saves the cleaned list back if it changed.
+// deduplicates, and saves the cleaned list back if it changed. Repos whose
+// path is gone are kept on the list but left out of the result; knownRepos
+// reports them.
 func canonicalRepos() ([]string, error) {
-	repos, err := globalconfig.GetKnownRepos()
+	repos, _, err := knownRepos()
+	return repos, err
+}
+
+// knownRepos is like canonicalRepos but also returns the known repos whose
+// path no longer exists or is no longer a git repository, e.g. on an
+// unmounted disk.
+func knownRepos() (repos, missing []string, err error) {
+	stored, err := globalconfig.GetKnownRepos()
 	if err != nil {
-		return nil, fmt.Errorf("loading repos: %w", err)
+		return nil, nil, fmt.Errorf("loading repos: %w", err)
 	}
 
+	roots := map[string]string{}
 	seen := map[string]bool{}
-	var canonical []string
-	for _, r := range repos {
+	var kept []string
+	for _, r := range stored {
+		root := r
 		main, err := git.MainRepoRootOf(r)
+		if err == nil {
+			root = main
+		}
+		roots[r] = root
+		if seen[root] {
+			continue
+		}
+		seen[root] = true
+		kept = append(kept, root)
 		if err != nil {
-			continue // path gone or not a git repo — drop it
+			missing = append(missing, root)
+		} else {
+			repos = append(repos, root)
 		}
-		if !seen[main] {
-			seen[main] = true
-			canonical = append(canonical, main)
+	}
+	if !slices.Equal(kept, stored) {
+		// Apply the same cleanup to the state as it is now, in case another
+		// wtt registered a repo meanwhile
+		_ = globalconfig.UpdateState(func(s *globalconfig.State) error {
+			seen := map[string]bool{}
+			var cleaned []globalconfig.Repo
+			for _, r := range s.Repos {
+				if root, ok := roots[r.Path]; ok {
+					r.Path = root
+				}
+				if !seen[r.Path] {
+					seen[r.Path] = true
+					cleaned = append(cleaned, r)
+				}
+			}
+			s.Repos = cleaned
+			return nil
+		})
+	}
+	return repos, missing, nil
+}
+
+// findRepo resolves name against the known repos, trying in turn an alias
+// set with "wtt repo alias", a repo directory name, a path, and a unique
+// substring of a directory name.
+func findRepo(repos []string, name string) (string, error) {
+	if aliases, err := globalconfig.GetAliases(); err == nil {
+		if path, ok := aliases[name]; ok {
+			return path, nil
+		}
+	}
+
+	var matches []string
+	for _, r := range repos {
+		if filepath.Base(r) == name {
+			matches = append(matches, r)
+		}
+	}
+	if len(matches) > 1 {
+		return "", fmt.Errorf("%q is ambiguous: %s (tell them apart with 'wtt repo alias')", name, strings.Join(matches, ", "))
+	}
+	if len(matches) == 1 {
+		return matches[0], nil
+	}
+
+	if info, err := os.Stat(name); err == nil && info.IsDir() {
+		if abs, err := filepath.Abs(name); err == nil {
+			if main, err := git.MainRepoRootOf(abs); err == nil {
+				for _, r := range repos {
+					if r == main {
+						return r, nil
+					}
+				}
+			}
+		}
+	}
+
+	for _, r := range repos {
+		if strings.Contains(strings.ToLower(filepath.Base(r)), strings.ToLower(name)) {
+			matches = append(matches, r)
 		}
 	}
-	if len(canonical) != len(repos) {
-		_ = globalconfig.SetKnownRepos(canonical)
+	switch len(matches) {
+	case 0:
+		return "", fmt.Errorf("no known repo matches %q (see 'wtt repo list')", name)
+	case 1:
+		return matches[0], nil
 	}
-	return canonical, nil
+	return "", fmt.Errorf("%q is ambiguous: %s", name, strings.Join(matches, ", "))
 }
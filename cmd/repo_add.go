@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/songtov/wtt/internal/git"
+	"github.com/songtov/wtt/internal/globalconfig"
+	"github.com/spf13/cobra"
+)
+
+var repoAddCmd = &cobra.Command{
+	Use:   "add [path...]",
+	Short: "Register repositories with wtt",
+	Long: `Add repositories to the known repos list, so they show up in 'wtt repo use'.
+Paths may point anywhere inside a repo or one of its worktrees; the main repo
+root is registered. Defaults to the current directory.`,
+	Args: cobra.ArbitraryArgs,
+	RunE: runRepoAdd,
+}
+
+func init() {
+	repoCmd.AddCommand(repoAddCmd)
+}
+
+func runRepoAdd(_ *cobra.Command, args []string) error {
+	if len(args) == 0 {
+		args = []string{"."}
+	}
+
+	var failed bool
+	for _, arg := range args {
+		abs, err := filepath.Abs(arg)
+		if err != nil {
+			return err
+		}
+		root, err := git.MainRepoRootOf(abs)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %s is not inside a git repository\n", arg)
+			failed = true
+			continue
+		}
+		added, err := registerRepo(root)
+		if err != nil {
+			return err
+		}
+		if added {
+			fmt.Fprintf(os.Stderr, "Added %s\n", root)
+		} else {
+			fmt.Fprintf(os.Stderr, "Already known: %s\n", root)
+		}
+	}
+	if failed {
+		return fmt.Errorf("some paths could not be added")
+	}
+	return nil
+}
+
+// registerRepo adds root to the known repos and reports whether it was new.
+func registerRepo(root string) (bool, error) {
+	repos, err := globalconfig.GetKnownRepos()
+	if err != nil {
+		return false, fmt.Errorf("loading repos: %w", err)
+	}
+	for _, r := range repos {
+		if r == root {
+			return false, nil
+		}
+	}
+	if err := globalconfig.RegisterRepo(root); err != nil {
+		return false, fmt.Errorf("registering %s: %w", root, err)
+	}
+	return true, nil
+}
@@ -0,0 +1,105 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/songtov/wtt/internal/globalconfig"
+	"github.com/spf13/cobra"
+)
+
+var repoAliasDelete bool
+
+var repoAliasCmd = &cobra.Command{
+	Use:   "alias [name [repo]]",
+	Short: "Name a repository for 'wtt repo use'",
+	Long: `Give a repository an alias that 'wtt repo use' and 'wtt repo remove' accept,
+e.g. to tell apart two repos both called "api". The repo is given by name or
+path. Without arguments, list the aliases.`,
+	Example: `  wtt repo alias work-api ~/work/api
+  wtt repo alias oss-api ~/src/api
+  wtt repo alias -d oss-api`,
+	Args:              cobra.MaximumNArgs(2),
+	ValidArgsFunction: completeAliasArgs,
+	RunE:              runRepoAlias,
+}
+
+func init() {
+	repoAliasCmd.Flags().BoolVarP(&repoAliasDelete, "delete", "d", false, "Delete the alias")
+	repoCmd.AddCommand(repoAliasCmd)
+}
+
+func runRepoAlias(_ *cobra.Command, args []string) error {
+	aliases, err := globalconfig.GetAliases()
+	if err != nil {
+		return fmt.Errorf("loading aliases: %w", err)
+	}
+
+	switch {
+	case repoAliasDelete:
+		if len(args) != 1 {
+			return fmt.Errorf("usage: wtt repo alias -d <name>")
+		}
+		if _, ok := aliases[args[0]]; !ok {
+			return fmt.Errorf("no alias %q", args[0])
+		}
+		if err := globalconfig.DeleteAlias(args[0]); err != nil {
+			return fmt.Errorf("saving aliases: %w", err)
+		}
+		fmt.Fprintf(os.Stderr, "Deleted alias %s\n", args[0])
+		return nil
+
+	case len(args) == 0:
+		names := make([]string, 0, len(aliases))
+		for name := range aliases {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		for _, name := range names {
+			fmt.Fprintf(tw, "%s\t%s\n", name, aliases[name])
+		}
+		return tw.Flush()
+
+	case len(args) == 1:
+		return fmt.Errorf("usage: wtt repo alias <name> <repo>")
+	}
+
+	name := args[0]
+	if name == "-" || strings.ContainsAny(name, "/\\ \t\n") {
+		return fmt.Errorf("invalid alias %q: must not be \"-\" or contain slashes or spaces", name)
+	}
+	repos, err := canonicalRepos()
+	if err != nil {
+		return err
+	}
+	repo, err := resolveRepoArg(repos, args[1])
+	if err != nil {
+		return err
+	}
+	if err := globalconfig.SetAlias(name, repo); err != nil {
+		return fmt.Errorf("saving aliases: %w", err)
+	}
+	fmt.Fprintf(os.Stderr, "Alias %s → %s\n", name, repo)
+	return nil
+}
+
+// completeAliasArgs completes existing aliases for -d, and repos for the
+// second argument.
+func completeAliasArgs(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if repoAliasDelete && len(args) == 0 {
+		aliases, _ := globalconfig.GetAliases()
+		var comps []string
+		for name, path := range aliases {
+			comps = append(comps, name+"\t"+path)
+		}
+		return comps, cobra.ShellCompDirectiveNoFileComp
+	}
+	if len(args) == 1 {
+		return completeRepos(cmd, nil, toComplete)
+	}
+	return nil, cobra.ShellCompDirectiveNoFileComp
+}
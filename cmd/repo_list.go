@@ -1,57 +1,347 @@
 package cmd
 
 import (
+	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
 
-	"github.com/songtov/wtt/internal/fzf"
+	"github.com/songtov/wtt/internal/git"
 	"github.com/songtov/wtt/internal/globalconfig"
+	"github.com/songtov/wtt/internal/history"
 	"github.com/spf13/cobra"
 )
 
+var (
+	repoListPrint bool
+	repoListJSON  bool
+)
+
 var repoListCmd = &cobra.Command{
-	Use:   "list",
+	Use:   "list [name]",
 	Short: "Pick and switch the active repository context",
-	Args:  cobra.NoArgs,
-	RunE:  runRepoList,
+	Long: `Pick a known repository and make it the active context, like 'wtt repo use'.
+Each repo is shown with its worktree count, how many worktrees have
+uncommitted changes, and when you last navigated in it; ● marks the active
+context. Pass a repo name (or path) to switch to it directly without the
+picker, or --print / --json to list the repos without switching.
+
+Known repos whose path has disappeared (e.g. on an unmounted disk) are
+reported, and the interactive list offers to relocate or drop them. Repos
+you choose to keep aren't asked about again.`,
+	Args:              cobra.MaximumNArgs(1),
+	ValidArgsFunction: completeRepos,
+	RunE:              runRepoList,
 }
 
 func init() {
+	repoListCmd.Flags().BoolVarP(&repoUseGlobal, "global", "g", false, "Switch the context for all shell sessions")
+	repoListCmd.Flags().BoolVar(&repoListPrint, "print", false, "Print the repos as a table instead of picking one")
+	repoListCmd.Flags().BoolVar(&repoListJSON, "json", false, "Print the repos as JSON instead of picking one")
 	repoCmd.AddCommand(repoListCmd)
 }
 
-func runRepoList(_ *cobra.Command, _ []string) error {
-	repos, err := canonicalRepos()
+// repoSummary describes a known repo for "wtt repo list".
+type repoSummary struct {
+	Name      string     `json:"name"`
+	Path      string     `json:"path"`
+	Aliases   []string   `json:"aliases,omitempty"`
+	Current   bool       `json:"current"`
+	Missing   bool       `json:"missing"`
+	Worktrees int        `json:"worktrees"`
+	Dirty     int        `json:"dirty"`
+	LastUsed  *time.Time `json:"last_used,omitempty"`
+}
+
+func runRepoList(cmd *cobra.Command, args []string) error {
+	if !repoListPrint && !repoListJSON {
+		if len(args) == 0 {
+			if err := resolveMissingRepos(); err != nil {
+				return err
+			}
+		}
+		return runRepoUse(cmd, args)
+	}
+
+	repos, missing, err := knownRepos()
 	if err != nil {
 		return err
 	}
-	if len(repos) == 0 {
-		fmt.Fprintln(os.Stderr, "No repos registered yet. Run any wtt command from inside a git repo first.")
-		return nil
+	summaries := summarizeRepos(repos, missing)
+
+	if repoListJSON {
+		if summaries == nil {
+			summaries = []repoSummary{}
+		}
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(summaries)
+	}
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "  NAME\tWORKTREES\tDIRTY\tLAST USED\tPATH")
+	for _, s := range summaries {
+		marker := "  "
+		if s.Current {
+			marker = "● "
+		}
+		name := s.Name
+		if len(s.Aliases) > 0 {
+			name += " (" + strings.Join(s.Aliases, ", ") + ")"
+		}
+		worktrees, dirty := strconv.Itoa(s.Worktrees), strconv.Itoa(s.Dirty)
+		if s.Missing {
+			worktrees, dirty = "missing", "-"
+		}
+		lastUsed := "-"
+		if s.LastUsed != nil {
+			lastUsed = humanizeAge(*s.LastUsed)
+		}
+		fmt.Fprintf(tw, "%s%s\t%s\t%s\t%s\t%s\n", marker, name, worktrees, dirty, lastUsed, s.Path)
+	}
+	return tw.Flush()
+}
+
+// summarizeRepos gathers a summary of each repo, running the git commands
+// for different repos concurrently. Missing repos come last.
+func summarizeRepos(repos, missing []string) []repoSummary {
+	current, _ := globalconfig.GetCurrentRepo()
+	aliases, _ := globalconfig.GetAliases()
+	aliasesOf := map[string][]string{}
+	for name, path := range aliases {
+		aliasesOf[path] = append(aliasesOf[path], name)
+	}
+
+	summaries := make([]repoSummary, len(repos)+len(missing))
+	var wg sync.WaitGroup
+	for i, r := range repos {
+		summaries[i] = repoSummary{Name: filepath.Base(r), Path: r, Current: r == current, Aliases: aliasesOf[r]}
+		wg.Add(1)
+		go func(s *repoSummary) {
+			defer wg.Done()
+			s.fill()
+		}(&summaries[i])
+	}
+	for i, r := range missing {
+		summaries[len(repos)+i] = repoSummary{Name: filepath.Base(r), Path: r, Current: r == current, Aliases: aliasesOf[r], Missing: true}
+	}
+	wg.Wait()
+	return summaries
+}
+
+// statusSlots bounds how many git status runs summarizeRepos has going at
+// once across all repos.
+var statusSlots = make(chan struct{}, 8)
+
+// fill counts the repo's worktrees and dirty worktrees, checking the
+// worktrees concurrently, and finds the last visit in its history.
+func (s *repoSummary) fill() {
+	if worktrees, err := git.ListWorktreesIn(s.Path); err == nil {
+		s.Worktrees = len(worktrees)
+		var mu sync.Mutex
+		var wg sync.WaitGroup
+		for _, wt := range worktrees {
+			wg.Add(1)
+			go func(path string) {
+				defer wg.Done()
+				statusSlots <- struct{}{}
+				dirty, err := git.IsDirty(path)
+				<-statusSlots
+				if err == nil && dirty {
+					mu.Lock()
+					s.Dirty++
+					mu.Unlock()
+				}
+			}(wt.Path)
+		}
+		wg.Wait()
+	}
+	if visits, err := history.Load(s.Path); err == nil {
+		for _, t := range history.LastVisited(visits) {
+			if s.LastUsed == nil || t.After(*s.LastUsed) {
+				last := t
+				s.LastUsed = &last
+			}
+		}
+	}
+}
+
+// label is the picker detail for the repo.
+func (s *repoSummary) label() string {
+	parts := []string{fmt.Sprintf("%d worktrees", s.Worktrees)}
+	if s.Worktrees == 1 {
+		parts[0] = "1 worktree"
+	}
+	if s.Dirty > 0 {
+		parts = append(parts, fmt.Sprintf("%d dirty", s.Dirty))
+	}
+	if s.LastUsed != nil {
+		parts = append(parts, "used "+humanizeAge(*s.LastUsed))
+	}
+	label := strings.Join(parts, ", ")
+	if s.Current {
+		label = "● " + label
 	}
+	return label
+}
 
-	selected, noneSelected, err := fzf.SelectRepoWithNone(repos)
+// repoDetails returns picker details for repos, keyed by path.
+func repoDetails(repos []string) map[string]string {
+	details := map[string]string{}
+	for _, s := range summarizeRepos(repos, nil) {
+		details[s.Path] = s.label()
+	}
+	return details
+}
+
+// resolveMissingRepos asks what to do about each known repo whose path is
+// gone: relocate it, drop it, or keep it for when it comes back. Keeping is
+// remembered, so the question isn't repeated on every run.
+func resolveMissingRepos() error {
+	_, missing, err := knownRepos()
+	if err != nil || len(missing) == 0 {
+		return err
+	}
+	state, err := globalconfig.LoadState()
 	if err != nil {
 		return err
 	}
+	kept := map[string]bool{}
+	for _, r := range state.Repos {
+		kept[r.Path] = r.KeepMissing
+	}
+	for _, r := range missing {
+		if kept[r] {
+			continue
+		}
+		answer := strings.ToLower(ask(fmt.Sprintf("Repo %s is missing (%s). [r]elocate, [d]rop or [k]eep? [k] ", filepath.Base(r), r)))
+		switch answer {
+		case "r", "relocate":
+			to := ask("New path: ")
+			if to == "" {
+				continue
+			}
+			root, err := git.MainRepoRootOf(expandHome(to))
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: %s is not a git repository; keeping %s\n", to, r)
+				continue
+			}
+			if err := relocateRepo(r, root); err != nil {
+				return err
+			}
+			fmt.Fprintf(os.Stderr, "Relocated %s → %s\n", r, root)
+		case "d", "drop":
+			if err := forgetRepo(r); err != nil {
+				return err
+			}
+			fmt.Fprintf(os.Stderr, "Dropped %s\n", r)
+		default:
+			if err := keepMissingRepo(r); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// keepMissingRepo marks the missing repo as kept, so resolveMissingRepos
+// doesn't ask about it again.
+func keepMissingRepo(repo string) error {
+	err := globalconfig.UpdateState(func(s *globalconfig.State) error {
+		for i, r := range s.Repos {
+			if r.Path == repo {
+				s.Repos[i].KeepMissing = true
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("updating repos: %w", err)
+	}
+	return nil
+}
 
-	if noneSelected {
-		if err := globalconfig.ClearCurrentRepo(); err != nil {
-			return fmt.Errorf("clearing repo context: %w", err)
+// relocateRepo replaces from with to in the known repos, aliases and global
+// context.
+func relocateRepo(from, to string) error {
+	err := globalconfig.UpdateState(func(s *globalconfig.State) error {
+		for i, r := range s.Repos {
+			if r.Path == from {
+				s.Repos[i].Path = to
+			}
+		}
+		for name, path := range s.Aliases {
+			if path == from {
+				s.Aliases[name] = to
+			}
+		}
+		if s.CurrentRepo == from {
+			s.CurrentRepo = to
+		}
+		if s.PreviousRepo == from {
+			s.PreviousRepo = to
 		}
-		fmt.Fprintln(os.Stderr, "Cleared repo context.")
 		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("updating repos: %w", err)
 	}
+	return nil
+}
 
-	if selected == "" {
-		return nil // user cancelled
+// forgetRepo removes repo from the known repos and any context or alias
+// pointing at it.
+func forgetRepo(repo string) error {
+	err := globalconfig.UpdateState(func(s *globalconfig.State) error {
+		s.RemoveRepo(repo)
+		for name, path := range s.Aliases {
+			if path == repo {
+				delete(s.Aliases, name)
+			}
+		}
+		if s.CurrentRepo == repo {
+			s.CurrentRepo = ""
+		}
+		if s.PreviousRepo == repo {
+			s.PreviousRepo = ""
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("removing repo: %w", err)
 	}
+	return nil
+}
 
-	if err := globalconfig.SetCurrentRepo(selected); err != nil {
-		return fmt.Errorf("saving repo context: %w", err)
+// humanizeAge formats how long ago t was, e.g. "5m ago" or "3d ago".
+func humanizeAge(t time.Time) string {
+	d := time.Since(t)
+	switch {
+	case d < time.Minute:
+		return "just now"
+	case d < time.Hour:
+		return fmt.Sprintf("%dm ago", int(d.Minutes()))
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%dh ago", int(d.Hours()))
+	case d < 14*24*time.Hour:
+		return fmt.Sprintf("%dd ago", int(d.Hours()/24))
+	case d < 365*24*time.Hour:
+		return fmt.Sprintf("%dw ago", int(d.Hours()/24/7))
 	}
+	return t.Format("2006-01-02")
+}
 
-	fmt.Fprintf(os.Stderr, "Switched to repo: %s\n", filepath.Base(selected))
-	return nil
+// expandHome expands a leading "~/" in path.
+func expandHome(path string) string {
+	if rest, ok := strings.CutPrefix(path, "~/"); ok {
+		if home, err := os.UserHomeDir(); err == nil {
+			return filepath.Join(home, rest)
+		}
+	}
+	return path
 }
@@ -1,24 +1,24 @@
 package cmd
 
 import (
-	"bufio"
 	"fmt"
 	"os"
 	"path/filepath"
-	"strings"
 
-	"github.com/songtov/wtt/internal/fzf"
+	"github.com/songtov/wtt/internal/directive"
 	"github.com/songtov/wtt/internal/globalconfig"
+	"github.com/songtov/wtt/internal/picker"
 	"github.com/spf13/cobra"
 )
 
 var repoRemoveForce bool
 
 var repoRemoveCmd = &cobra.Command{
-	Use:   "remove",
-	Short: "Remove a repository from the known repos list",
-	Args:  cobra.NoArgs,
-	RunE:  runRepoRemove,
+	Use:               "remove [name]",
+	Short:             "Remove a repository from the known repos list",
+	Args:              cobra.MaximumNArgs(1),
+	ValidArgsFunction: completeRepos,
+	RunE:              runRepoRemove,
 }
 
 func init() {
@@ -26,17 +26,24 @@ func init() {
 	repoCmd.AddCommand(repoRemoveCmd)
 }
 
-func runRepoRemove(_ *cobra.Command, _ []string) error {
-	repos, err := canonicalRepos()
+func runRepoRemove(_ *cobra.Command, args []string) error {
+	// Missing repos can be removed too
+	repos, missing, err := knownRepos()
 	if err != nil {
 		return err
 	}
+	repos = append(repos, missing...)
 	if len(repos) == 0 {
 		fmt.Fprintln(os.Stderr, "No repos registered yet.")
 		return nil
 	}
 
-	selected, err := fzf.SelectRepo(repos)
+	var selected string
+	if len(args) == 1 {
+		selected, err = findRepo(repos, args[0])
+	} else {
+		selected, err = picker.SelectRepo(repos, nil)
+	}
 	if err != nil {
 		return err
 	}
@@ -45,22 +52,24 @@ func runRepoRemove(_ *cobra.Command, _ []string) error {
 	}
 
 	if !repoRemoveForce {
-		fmt.Fprintf(os.Stderr, "Remove %s from known repos? [y/N] ", filepath.Base(selected))
-		scanner := bufio.NewScanner(os.Stdin)
-		scanner.Scan()
-		if strings.ToLower(strings.TrimSpace(scanner.Text())) != "y" {
+		if !confirm(fmt.Sprintf("Remove %s from known repos? [y/N] ", filepath.Base(selected))) {
 			fmt.Fprintln(os.Stderr, "Aborted.")
 			return nil
 		}
 	}
 
-	if err := globalconfig.RemoveRepo(selected); err != nil {
-		return fmt.Errorf("removing repo: %w", err)
+	cleared := false
+	if global, _ := globalconfig.GetGlobalRepo(); global == selected {
+		cleared = true
 	}
-
-	current, _ := globalconfig.GetCurrentRepo()
-	if current == selected {
-		_ = globalconfig.ClearCurrentRepo()
+	if err := forgetRepo(selected); err != nil {
+		return err
+	}
+	if session, ok := globalconfig.SessionRepo(); ok && session == selected {
+		_ = directive.Export(globalconfig.SessionEnvVar, "")
+		cleared = true
+	}
+	if cleared {
 		fmt.Fprintf(os.Stderr, "Removed %s and cleared repo context.\n", filepath.Base(selected))
 	} else {
 		fmt.Fprintf(os.Stderr, "Removed %s from known repos.\n", filepath.Base(selected))
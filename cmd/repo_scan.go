@@ -0,0 +1,125 @@
+package cmd
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/songtov/wtt/internal/git"
+	"github.com/spf13/cobra"
+)
+
+var repoScanDepth int
+
+// scanSkipDirs are not descended into while scanning, unless they are
+// repositories themselves.
+var scanSkipDirs = map[string]bool{
+	"node_modules": true,
+	"vendor":       true,
+	"target":       true,
+	"dist":         true,
+	"build":        true,
+}
+
+var repoScanCmd = &cobra.Command{
+	Use:   "scan [dir]",
+	Short: "Find and register every repository under a directory",
+	Long: `Walk a directory tree (default: the current directory) looking for git
+repositories and register their main repo roots in bulk. Repositories and
+worktrees are not descended into, nor are hidden directories, node_modules
+and other dependency or build directories that aren't repositories
+themselves.`,
+	Example: `  wtt repo scan ~/src
+  wtt repo scan ~ --depth 4`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runRepoScan,
+}
+
+func init() {
+	repoScanCmd.Flags().IntVar(&repoScanDepth, "depth", 3, "How many directory levels below dir to search")
+	repoCmd.AddCommand(repoScanCmd)
+}
+
+func runRepoScan(_ *cobra.Command, args []string) error {
+	dir := "."
+	if len(args) == 1 {
+		dir = args[0]
+	}
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return err
+	}
+	if !isDir(dir) {
+		return fmt.Errorf("%s is not a directory", dir)
+	}
+	// Match the physical paths git reports for registered repos
+	if resolved, err := filepath.EvalSymlinks(dir); err == nil {
+		dir = resolved
+	}
+
+	roots := scanRepos(dir, repoScanDepth)
+
+	var added int
+	for _, root := range roots {
+		ok, err := registerRepo(root)
+		if err != nil {
+			return err
+		}
+		if ok {
+			fmt.Fprintf(os.Stderr, "Added %s\n", root)
+			added++
+		}
+	}
+	fmt.Fprintf(os.Stderr, "Found %d repos, %d new.\n", len(roots), added)
+	return nil
+}
+
+// scanRepos returns the main roots of the repositories found under dir, at
+// most depth levels down, in walk order and without duplicates.
+func scanRepos(dir string, depth int) []string {
+	seen := map[string]bool{}
+	var roots []string
+
+	_ = filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			// Unreadable directory: skip it, keep scanning the rest
+			if d != nil && d.IsDir() && path != dir {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		if _, err := os.Stat(filepath.Join(path, ".git")); err == nil {
+			// A repo, linked worktree or submodule; resolve it without
+			// running git, and don't look inside
+			if loc, err := git.Locate(path); err == nil && !seen[loc.MainRoot] && isDir(loc.MainRoot) {
+				seen[loc.MainRoot] = true
+				roots = append(roots, loc.MainRoot)
+			}
+			return filepath.SkipDir
+		}
+		name := d.Name()
+		if path != dir && (strings.HasPrefix(name, ".") || scanSkipDirs[name]) {
+			return filepath.SkipDir
+		}
+
+		level := 0
+		if rel, _ := filepath.Rel(dir, path); rel != "." {
+			level = strings.Count(rel, string(filepath.Separator)) + 1
+		}
+		if level >= depth {
+			return filepath.SkipDir
+		}
+		return nil
+	})
+	return roots
+}
+
+func isDir(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
+}
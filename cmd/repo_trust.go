@@ -0,0 +1,201 @@
+package cmd
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+
+	"github.com/songtov/wtt/internal/config"
+	"github.com/songtov/wtt/internal/globalconfig"
+	"github.com/spf13/cobra"
+)
+
+var repoTrustRevoke bool
+
+var repoTrustCmd = &cobra.Command{
+	Use:   "trust [name | path]",
+	Short: "Allow a repository's .wtt.toml to set up your shell",
+	Long: `Allow wtt to apply the env and source_files settings of a repository's
+.wtt.toml when navigating into its worktrees. They come from the repository,
+so they are ignored until you trust them: exported variables and sourced
+files run in your shell.
+
+The trust covers the settings and the contents of the source_files as they
+are now, in the current worktree (the main worktree for a named repo). When
+env or source_files change, they are ignored again until you re-run 'wtt
+repo trust'; a sourced file whose contents differ, e.g. on another branch,
+is skipped until you trust it from a worktree that has it. Defaults to the
+current repo; --revoke withdraws the trust.`,
+	Args:              cobra.MaximumNArgs(1),
+	ValidArgsFunction: completeRepos,
+	RunE:              runRepoTrust,
+}
+
+func init() {
+	repoTrustCmd.Flags().BoolVar(&repoTrustRevoke, "revoke", false, "Stop applying the repo's env and source_files")
+	repoCmd.AddCommand(repoTrustCmd)
+}
+
+func runRepoTrust(_ *cobra.Command, args []string) error {
+	var repoRoot string
+	var err error
+	if len(args) == 1 {
+		repos, reposErr := canonicalRepos()
+		if reposErr != nil {
+			return reposErr
+		}
+		repoRoot, err = resolveRepoArg(repos, args[0])
+	} else {
+		repoRoot, err = repoRootWithFallback()
+		if err == nil {
+			autoRegisterRepo(repoRoot)
+		}
+	}
+	if err != nil {
+		return err
+	}
+	name := filepath.Base(repoRoot)
+	// The source_files are read from the worktree the user is looking at
+	worktreePath := repoRoot
+	if len(args) == 0 {
+		if cur := currentWorktree(repoRoot); cur != "" {
+			worktreePath = cur
+		}
+	}
+
+	if repoTrustRevoke {
+		if err := setTrustedEnv(repoRoot, "", nil); err != nil {
+			return err
+		}
+		fmt.Fprintf(os.Stderr, "%s: env and source_files are no longer applied.\n", name)
+		return nil
+	}
+
+	cfg, err := config.Load(repoRoot, name)
+	if err != nil {
+		return err
+	}
+	fingerprint := cfg.EnvFingerprint()
+	if fingerprint == "" {
+		fmt.Fprintf(os.Stderr, "%s: .wtt.toml sets no env or source_files; nothing to trust.\n", name)
+		return nil
+	}
+	var hashes []string
+	present := map[string]bool{}
+	for _, f := range cfg.SourceFiles {
+		if hash := sourceFileHash(f, filepath.Join(worktreePath, f)); hash != "" {
+			hashes = append(hashes, hash)
+			present[f] = true
+		}
+	}
+	if err := setTrustedEnv(repoRoot, fingerprint, hashes); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(os.Stderr, "Trusted %s; on navigation wtt now applies:\n", name)
+	keys := make([]string, 0, len(cfg.Env))
+	for k := range cfg.Env {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		fmt.Fprintf(os.Stderr, "  export %s=%s\n", k, cfg.Env[k])
+	}
+	for _, f := range cfg.SourceFiles {
+		if present[f] {
+			fmt.Fprintf(os.Stderr, "  source %s (as in %s)\n", f, worktreePath)
+		} else {
+			fmt.Fprintf(os.Stderr, "  source %s: not in %s, so no contents are trusted yet\n", f, worktreePath)
+		}
+	}
+	return nil
+}
+
+// sourceFileHash identifies the contents of file, the source_files entry
+// name in some worktree, or returns "" if it can't be read.
+func sourceFileHash(name, file string) string {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return ""
+	}
+	h := sha256.New()
+	fmt.Fprintf(h, "%s\x00", name)
+	h.Write(data)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// setTrustedEnv records fingerprint as the trusted env settings of the
+// known repo at repoRoot, adding hashes to its trusted source_files
+// contents. An empty fingerprint revokes the trust, contents included.
+func setTrustedEnv(repoRoot, fingerprint string, hashes []string) error {
+	found := false
+	err := globalconfig.UpdateState(func(s *globalconfig.State) error {
+		for i, r := range s.Repos {
+			if r.Path != repoRoot {
+				continue
+			}
+			found = true
+			s.Repos[i].TrustedEnv = fingerprint
+			if fingerprint == "" {
+				s.Repos[i].TrustedFiles = nil
+				continue
+			}
+			for _, h := range hashes {
+				if !slices.Contains(s.Repos[i].TrustedFiles, h) {
+					s.Repos[i].TrustedFiles = append(s.Repos[i].TrustedFiles, h)
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("updating repos: %w", err)
+	}
+	if !found {
+		return fmt.Errorf("%s is not a known repo", repoRoot)
+	}
+	return nil
+}
+
+// envTrusted reports whether the env and source_files settings in cfg were
+// trusted with "wtt repo trust", and if so returns the hashes of the
+// source_files contents trusted along with them. The first time untrusted
+// settings are seen, the user is told how to enable them.
+func envTrusted(repoRoot string, cfg *config.Config) ([]string, bool) {
+	fingerprint := cfg.EnvFingerprint()
+	state, err := globalconfig.LoadState()
+	if err != nil {
+		return nil, false
+	}
+	var repo globalconfig.Repo
+	for _, r := range state.Repos {
+		if r.Path == repoRoot {
+			repo = r
+		}
+	}
+	if repo.TrustedEnv == fingerprint {
+		return repo.TrustedFiles, true
+	}
+	if repo.EnvWarned == fingerprint {
+		return nil, false
+	}
+
+	what := "changed env or source_files"
+	if repo.TrustedEnv == "" {
+		what = "env or source_files"
+	}
+	fmt.Fprintf(os.Stderr, "Warning: %s sets %s, which wtt ignores until you review them and run 'wtt repo trust'\n", config.Path(repoRoot), what)
+	_ = globalconfig.UpdateState(func(s *globalconfig.State) error {
+		for i, r := range s.Repos {
+			if r.Path == repoRoot {
+				s.Repos[i].EnvWarned = fingerprint
+			}
+		}
+		return nil
+	})
+	return nil, false
+}
@@ -0,0 +1,139 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/songtov/wtt/internal/directive"
+	"github.com/songtov/wtt/internal/git"
+	"github.com/songtov/wtt/internal/globalconfig"
+	"github.com/songtov/wtt/internal/picker"
+	"github.com/spf13/cobra"
+)
+
+var repoUseGlobal bool
+
+var repoUseCmd = &cobra.Command{
+	Use:   "use [name | path]",
+	Short: "Switch the active repository context",
+	Long: `Make a repository the active context, so wtt commands operate on it even
+when run from outside it. Without an argument, pick one interactively.
+
+The context applies to the current shell session only (it is kept in
+$WTT_CONTEXT by the shell wrapper). With --global it becomes the default for
+every session that hasn't chosen its own.`,
+	Args:              cobra.MaximumNArgs(1),
+	ValidArgsFunction: completeRepos,
+	RunE:              runRepoUse,
+}
+
+func init() {
+	repoUseCmd.Flags().BoolVarP(&repoUseGlobal, "global", "g", false, "Switch the context for all shell sessions")
+	repoCmd.AddCommand(repoUseCmd)
+}
+
+func runRepoUse(cmd *cobra.Command, args []string) error {
+	repos, missing, err := knownRepos()
+	if err != nil {
+		return err
+	}
+
+	var selected string
+	var noneSelected bool
+	if len(args) == 1 {
+		selected, err = resolveRepoArg(repos, args[0])
+	} else {
+		if len(repos) == 0 {
+			fmt.Fprintln(os.Stderr, "No repos registered yet. Run any wtt command from inside a git repo first.")
+			return nil
+		}
+		// "repo list" has already asked about them
+		if len(missing) > 0 && cmd.Name() != "list" {
+			fmt.Fprintf(os.Stderr, "Warning: %d known repo(s) are missing; run 'wtt repo list' to relocate or drop them\n", len(missing))
+		}
+		selected, noneSelected, err = picker.SelectRepoWithNone(repos, repoDetails(repos))
+	}
+	if err != nil {
+		return err
+	}
+	if selected == "" && !noneSelected {
+		return nil // user cancelled
+	}
+	return useRepo(selected)
+}
+
+// runRepoBack implements "wtt repo -": switch back to the previous context,
+// like "cd -".
+func runRepoBack() error {
+	prev, err := globalconfig.PreviousRepo()
+	if err != nil {
+		return fmt.Errorf("loading previous repo context: %w", err)
+	}
+	if prev == "" {
+		return fmt.Errorf("no previous repo context")
+	}
+	return useRepo(prev)
+}
+
+// useRepo switches to repoPath ("" for none) in the scope chosen by --global
+// and reports the switch.
+func useRepo(repoPath string) error {
+	// Without the shell wrapper there is no session to update
+	global := repoUseGlobal
+	if !global && !directive.Active() {
+		fmt.Fprintln(os.Stderr, "Warning: shell integration is not loaded; switching the global context")
+		global = true
+	}
+	if err := switchRepo(repoPath, global); err != nil {
+		return err
+	}
+	scope := "this session"
+	if global {
+		scope = "all sessions"
+	}
+	if repoPath == "" {
+		fmt.Fprintf(os.Stderr, "Cleared repo context (%s).\n", scope)
+	} else {
+		fmt.Fprintf(os.Stderr, "Switched to repo: %s (%s)\n", filepath.Base(repoPath), scope)
+	}
+	return nil
+}
+
+// resolveRepoArg resolves a repo name or path. A path to a repo that isn't
+// known yet is registered.
+func resolveRepoArg(repos []string, arg string) (string, error) {
+	repo, err := findRepo(repos, arg)
+	if err == nil {
+		return repo, nil
+	}
+	if info, statErr := os.Stat(arg); statErr == nil && info.IsDir() {
+		if abs, absErr := filepath.Abs(arg); absErr == nil {
+			if main, gitErr := git.MainRepoRootOf(abs); gitErr == nil {
+				autoRegisterRepo(main)
+				return main, nil
+			}
+		}
+	}
+	return "", err
+}
+
+// switchRepo makes repoPath ("" for none) the active context of the current
+// shell session, and of every session when global is set. The context being
+// replaced is remembered for "wtt repo -".
+func switchRepo(repoPath string, global bool) error {
+	current, _ := globalconfig.GetCurrentRepo()
+	if global {
+		if err := globalconfig.SetGlobalRepo(repoPath); err != nil {
+			return fmt.Errorf("saving repo context: %w", err)
+		}
+	}
+	// Update the session too, so that a session which had chosen its own
+	// context follows a global switch made from it.
+	if current != repoPath && current != "" {
+		if err := directive.Export(globalconfig.SessionPreviousEnvVar, current); err != nil {
+			return err
+		}
+	}
+	return directive.Export(globalconfig.SessionEnvVar, repoPath)
+}
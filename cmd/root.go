@@ -3,21 +3,41 @@ package cmd
 import (
 	"fmt"
 	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
 
+	"github.com/songtov/wtt/internal/config"
+	"github.com/songtov/wtt/internal/directive"
 	"github.com/songtov/wtt/internal/git"
 	"github.com/songtov/wtt/internal/globalconfig"
+	"github.com/songtov/wtt/internal/history"
+	"github.com/songtov/wtt/internal/meta"
+	"github.com/songtov/wtt/internal/picker"
 	"github.com/songtov/wtt/internal/shell"
+	"github.com/songtov/wtt/internal/worktree"
 	"github.com/spf13/cobra"
 )
 
-var initShell string
+var (
+	initShell  string
+	rootCreate bool
+	keepSubdir bool
+	// keepSubdirSet records that --keep-subdir was given, so that
+	// --keep-subdir=false can override keep_subdir = true.
+	keepSubdirSet bool
+)
+
+const keepSubdirUsage = "Keep the current subdirectory when switching worktrees (default: keep_subdir from .wtt.toml)"
 
 var rootCmd = &cobra.Command{
-	Use:   "wtt [branch]",
-	Short: "Git worktree manager",
-	Long:  `wtt wraps git worktree to create, remove, and navigate worktrees easily.`,
-	Args:  cobra.MaximumNArgs(1),
-	RunE:  runRoot,
+	Use:               "wtt [branch | -]",
+	Short:             "Git worktree manager",
+	Long:              `wtt wraps git worktree to create, remove, and navigate worktrees easily.`,
+	Args:              cobra.MaximumNArgs(1),
+	ValidArgsFunction: completeWorktrees,
+	RunE:              runRoot,
 }
 
 func Execute() {
@@ -27,7 +47,15 @@ func Execute() {
 }
 
 func init() {
-	rootCmd.PersistentFlags().StringVar(&initShell, "init", "", "Print shell init function (zsh, bash, fish)")
+	rootCmd.PersistentFlags().StringVar(&initShell, "init", "", "Print shell init function (zsh, bash, fish, nu, pwsh, elvish, xonsh)")
+	_ = rootCmd.RegisterFlagCompletionFunc("init", cobra.FixedCompletions(shell.Supported, cobra.ShellCompDirectiveNoFileComp))
+	rootCmd.Flags().BoolVarP(&rootCreate, "create", "c", false, "Create the worktree if none matches (default: create_on_miss from .wtt.toml)")
+	rootCmd.Flags().BoolVar(&keepSubdir, "keep-subdir", false, keepSubdirUsage)
+	// --keep-subdir is defined on several commands; navigate needs to know
+	// whether the running one got it
+	rootCmd.PersistentPreRun = func(cmd *cobra.Command, _ []string) {
+		keepSubdirSet = cmd.Flags().Changed("keep-subdir")
+	}
 	rootCmd.AddCommand(createCmd)
 	rootCmd.AddCommand(removeCmd)
 	rootCmd.AddCommand(listCmd)
@@ -35,10 +63,18 @@ func init() {
 	rootCmd.AddCommand(repoCmd)
 	rootCmd.AddCommand(contextCmd)
 	rootCmd.AddCommand(initCmd)
+	rootCmd.AddCommand(syncCmd)
+	rootCmd.AddCommand(noteCmd)
+	rootCmd.AddCommand(statusCmd)
+	rootCmd.AddCommand(recentCmd)
+	rootCmd.AddCommand(previewCmd)
+	rootCmd.AddCommand(doctorCmd)
+	rootCmd.AddCommand(gcCmd)
 }
 
 // repoRootWithFallback returns the git repo root for the current directory.
-// When not inside a git repo it falls back to the context saved by "wtt repo".
+// When not inside a git repo it falls back to the context set by "wtt repo use"
+// for this shell session, or else the global one.
 func repoRootWithFallback() (string, error) {
 	root, err := git.MainRepoRoot()
 	if err == nil {
@@ -46,7 +82,7 @@ func repoRootWithFallback() (string, error) {
 	}
 	current, cfgErr := globalconfig.GetCurrentRepo()
 	if cfgErr != nil || current == "" {
-		return "", fmt.Errorf("not inside a git repository (run 'wtt repo' to set a repo context)")
+		return "", fmt.Errorf("not inside a git repository (run 'wtt repo use' to set a repo context)")
 	}
 	return current, nil
 }
@@ -64,7 +100,7 @@ func runRoot(cmd *cobra.Command, args []string) error {
 			return err
 		}
 		fmt.Print(fn)
-		return nil
+		return writeCompletion(cmd.Root(), os.Stdout, initShell)
 	}
 
 	if len(args) == 0 {
@@ -79,17 +115,278 @@ func runRoot(cmd *cobra.Command, args []string) error {
 	}
 	autoRegisterRepo(repoRoot)
 
+	if branch == "-" {
+		return navigateBack(repoRoot)
+	}
+
 	worktrees, err := git.ListWorktreesIn(repoRoot)
 	if err != nil {
 		return fmt.Errorf("listing worktrees: %w", err)
 	}
 
+	if wt := findWorktree(worktrees, branch); wt != nil {
+		return navigate(repoRoot, wt.Path)
+	}
+
+	matches := worktree.Match(worktrees, branch)
+	switch len(matches) {
+	case 0:
+		return createOnMiss(repoRoot, branch, cmd.Flags().Changed("create"))
+	case 1:
+		return navigate(repoRoot, matches[0].Path)
+	}
+
+	// Ambiguous: let the user choose among the matches
+	opts := pickerOptions(repoRoot)
+	opts.Query = branch
+	path, err := picker.Select(matches, opts)
+	if err != nil {
+		return err
+	}
+	if path == "" {
+		return nil // user cancelled
+	}
+	return navigate(repoRoot, path)
+}
+
+// navigate records a visit to path in the repo's history and asks the shell
+// wrapper to cd to it, or to the same subdirectory of it with --keep-subdir.
+// History is best-effort; failures are ignored.
+func navigate(repoRoot, path string) error {
+	cfg, err := config.Load(repoRoot, filepath.Base(repoRoot))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+
+	keep := cfg != nil && cfg.KeepSubdir
+	if keepSubdirSet {
+		keep = keepSubdir
+	}
+	target := path
+	if keep {
+		target = matchingSubdir(repoRoot, path)
+	}
+
+	if visits, err := history.Load(repoRoot); err == nil {
+		// If the user reached the current worktree without wtt, remember it
+		// too so "wtt -" can take them back.
+		visited := []string{path}
+		if cur := currentWorktree(repoRoot); cur != "" && cur != path && cur != history.Last(visits) {
+			visited = []string{cur, path}
+		}
+		_ = history.Record(repoRoot, visited...)
+	}
+	if err := directive.Cd(target); err != nil {
+		return err
+	}
+	if cfg != nil {
+		applyWorktreeEnv(repoRoot, path, cfg)
+	}
+	return nil
+}
+
+// matchingSubdir returns the directory in the worktree at path that sits at
+// the same relative location as the current directory does in its own
+// worktree, falling back to the nearest existing ancestor.
+func matchingSubdir(repoRoot, path string) string {
+	cur := currentWorktree(repoRoot)
+	if cur == "" {
+		return path
+	}
+	cwd, err := os.Getwd()
+	if err != nil {
+		return path
+	}
+	// git reports physical paths; resolve symlinks in cwd to match
+	if resolved, err := filepath.EvalSymlinks(cwd); err == nil {
+		cwd = resolved
+	}
+	rel, err := filepath.Rel(cur, cwd)
+	if err != nil || rel == "." || strings.HasPrefix(rel, "..") {
+		return path
+	}
+
+	for dir := filepath.Join(path, rel); dir != path; dir = filepath.Dir(dir) {
+		if info, err := os.Stat(dir); err == nil && info.IsDir() {
+			return dir
+		}
+	}
+	return path
+}
+
+// applyWorktreeEnv emits the env exports and source_files from .wtt.toml for
+// the worktree at path, once the user has trusted them. Problems are only
+// warned about: the cd itself has already been requested.
+func applyWorktreeEnv(repoRoot, path string, cfg *config.Config) {
+	if len(cfg.Env) == 0 && len(cfg.SourceFiles) == 0 {
+		return
+	}
+	trustedFiles, ok := envTrusted(repoRoot, cfg)
+	if !ok {
+		return
+	}
+	repoName := filepath.Base(repoRoot)
+
+	branch, _ := git.CurrentBranch(path)
+	expand := strings.NewReplacer("{path}", path, "{branch}", branch, "{repo}", repoName).Replace
+
+	keys := make([]string, 0, len(cfg.Env))
+	for k := range cfg.Env {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		if err := directive.Export(k, expand(cfg.Env[k])); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		}
+	}
+
+	for _, f := range cfg.SourceFiles {
+		file := filepath.Join(path, f)
+		if _, err := os.Stat(file); err != nil {
+			continue
+		}
+		// The contents come from the worktree's branch; only source what
+		// the user reviewed
+		if !slices.Contains(trustedFiles, sourceFileHash(f, file)) {
+			fmt.Fprintf(os.Stderr, "Warning: not sourcing %s: its contents aren't trusted; review it and run 'wtt repo trust' in this worktree\n", file)
+			continue
+		}
+		if err := directive.Source(file); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		}
+	}
+}
+
+// currentWorktree returns the root of the worktree containing the current
+// directory if it belongs to the repo at repoRoot, or "".
+func currentWorktree(repoRoot string) string {
+	cur, err := git.RepoRoot()
+	if err != nil {
+		return ""
+	}
+	if main, err := git.MainRepoRootOf(cur); err != nil || main != repoRoot {
+		return ""
+	}
+	return cur
+}
+
+// navigateBack implements "wtt -": go to the most recently visited worktree
+// other than the current one, like "cd -".
+func navigateBack(repoRoot string) error {
+	visits, err := history.Load(repoRoot)
+	if err != nil {
+		return fmt.Errorf("loading history: %w", err)
+	}
+	worktrees, err := git.ListWorktreesIn(repoRoot)
+	if err != nil {
+		return fmt.Errorf("listing worktrees: %w", err)
+	}
+	exists := map[string]bool{}
 	for _, wt := range worktrees {
-		if wt.Branch == branch || wt.Branch == "refs/heads/"+branch {
-			fmt.Println(wt.Path)
+		exists[wt.Path] = true
+	}
+
+	cur := currentWorktree(repoRoot)
+	for _, p := range history.Recent(visits) {
+		if p != cur && exists[p] {
+			return navigate(repoRoot, p)
+		}
+	}
+	return fmt.Errorf("no previous worktree")
+}
+
+// createOnMiss handles "wtt <branch>" when no worktree matches. If enabled by
+// --create or create_on_miss it creates the worktree the same way as
+// "wtt create", asking first when that means creating a brand new branch.
+// flagSet tells whether --create was given, in which case it overrides
+// create_on_miss either way.
+func createOnMiss(repoRoot, branch string, flagSet bool) error {
+	cfg, err := config.Load(repoRoot, filepath.Base(repoRoot))
+	if err != nil {
+		return fmt.Errorf("loading config: %w", err)
+	}
+	create := cfg.CreateOnMiss
+	if flagSet {
+		create = rootCreate
+	}
+	if !create {
+		return fmt.Errorf("no worktree found for branch %q", branch)
+	}
+	if err := git.ValidateBranchName(branch); err != nil {
+		return err
+	}
+
+	if !git.BranchExists(repoRoot, branch) && git.RemoteBranch(repoRoot, branch) == "" {
+		if !confirm(fmt.Sprintf("No branch %q. Create it from HEAD? [y/N] ", branch)) {
+			fmt.Fprintln(os.Stderr, "Aborted.")
 			return nil
 		}
 	}
 
-	return fmt.Errorf("no worktree found for branch %q", branch)
+	path, err := createWorktree(repoRoot, cfg, branch, "")
+	if err != nil {
+		return err
+	}
+	return navigate(repoRoot, path)
+}
+
+// confirm prints prompt to stderr and reports whether the user answered yes.
+func confirm(prompt string) bool {
+	answer := strings.ToLower(ask(prompt))
+	return answer == "y" || answer == "yes"
+}
+
+// ask prints prompt on stderr and returns the line typed in reply, trimmed.
+// It reads a byte at a time, so consecutive prompts can share piped input.
+func ask(prompt string) string {
+	fmt.Fprint(os.Stderr, prompt)
+	var line []byte
+	b := make([]byte, 1)
+	for {
+		n, err := os.Stdin.Read(b)
+		if n == 0 || err != nil || b[0] == '\n' {
+			break
+		}
+		line = append(line, b[0])
+	}
+	return strings.TrimSpace(string(line))
+}
+
+// findWorktree returns the worktree with the given branch checked out, or nil.
+func findWorktree(worktrees []git.Worktree, branch string) *git.Worktree {
+	for i, wt := range worktrees {
+		if wt.Branch == branch || wt.Branch == "refs/heads/"+branch {
+			return &worktrees[i]
+		}
+	}
+	return nil
+}
+
+// pickerOptions returns the worktree picker options for the repo: each
+// worktree's note and labels as details, and the configured preview command.
+func pickerOptions(repoRoot string) picker.Options {
+	opts := picker.Options{Details: worktreeDetails(repoRoot)}
+	settings, err := globalconfig.LoadSettings()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+	opts.Preview = settings.PreviewCommand
+	return opts
+}
+
+// worktreeDetails returns the picker details (note, PR, ...) of the repo's
+// worktrees, keyed by worktree path.
+func worktreeDetails(repoRoot string) map[string]string {
+	store, err := meta.Load(repoRoot)
+	if err != nil {
+		return nil
+	}
+	details := map[string]string{}
+	for path, e := range store.Worktrees {
+		if d := e.Summary(); d != "" {
+			details[path] = d
+		}
+	}
+	return details
 }
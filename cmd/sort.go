@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/songtov/wtt/internal/git"
+	"github.com/songtov/wtt/internal/history"
+	"github.com/songtov/wtt/internal/meta"
+)
+
+// sortModes lists the values accepted by --sort.
+var sortModes = []string{"frecency", "name", "recent", "created"}
+
+// sortWorktrees orders worktrees in place for display in a picker:
+//
+//	frecency  most frequently and recently visited first (default)
+//	name      alphabetically by branch
+//	recent    most recently visited first
+//	created   newest first, by recorded creation time
+//
+// Ties keep git's order, so unvisited worktrees stay main-first.
+func sortWorktrees(repoRoot string, worktrees []git.Worktree, mode string) error {
+	switch mode {
+	case "frecency", "recent":
+		visits, err := history.Load(repoRoot)
+		if err != nil {
+			return fmt.Errorf("loading history: %w", err)
+		}
+		if mode == "frecency" {
+			scores := history.Frecency(visits)
+			sort.SliceStable(worktrees, func(i, j int) bool {
+				return scores[worktrees[i].Path] > scores[worktrees[j].Path]
+			})
+		} else {
+			last := history.LastVisited(visits)
+			sort.SliceStable(worktrees, func(i, j int) bool {
+				return last[worktrees[i].Path].After(last[worktrees[j].Path])
+			})
+		}
+	case "name":
+		sort.SliceStable(worktrees, func(i, j int) bool {
+			return strings.TrimPrefix(worktrees[i].Branch, "refs/heads/") < strings.TrimPrefix(worktrees[j].Branch, "refs/heads/")
+		})
+	case "created":
+		store, err := meta.Load(repoRoot)
+		if err != nil {
+			return fmt.Errorf("loading metadata: %w", err)
+		}
+		created := func(wt git.Worktree) int64 {
+			// Entries without a creation time sort with the untracked ones
+			if e := store.Get(wt.Path); e != nil && !e.Created.IsZero() {
+				return e.Created.Unix()
+			}
+			return 0
+		}
+		sort.SliceStable(worktrees, func(i, j int) bool {
+			return created(worktrees[i]) > created(worktrees[j])
+		})
+	default:
+		return fmt.Errorf("invalid sort %q (want one of: %s)", mode, strings.Join(sortModes, ", "))
+	}
+	return nil
+}
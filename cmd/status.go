@@ -0,0 +1,74 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/songtov/wtt/internal/git"
+	"github.com/songtov/wtt/internal/meta"
+	"github.com/spf13/cobra"
+)
+
+var statusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show worktrees with their recorded metadata",
+	Long:  `Print every worktree of the active repo along with its base ref, creation time, creator, working tree state and note.`,
+	Args:  cobra.NoArgs,
+	RunE:  runStatus,
+}
+
+func runStatus(_ *cobra.Command, _ []string) error {
+	repoRoot, err := repoRootWithFallback()
+	if err != nil {
+		return err
+	}
+	autoRegisterRepo(repoRoot)
+
+	worktrees, err := git.ListWorktreesIn(repoRoot)
+	if err != nil {
+		return fmt.Errorf("listing worktrees: %w", err)
+	}
+	store, err := meta.Load(repoRoot)
+	if err != nil {
+		return fmt.Errorf("loading metadata: %w", err)
+	}
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "BRANCH\tSTATE\tBASE\tCREATED\tNOTE")
+	for _, wt := range worktrees {
+		branch := strings.TrimPrefix(wt.Branch, "refs/heads/")
+		if branch == "" {
+			branch = "(detached)"
+		}
+		if wt.IsMain {
+			branch = "● " + branch
+		} else {
+			branch = "  " + branch
+		}
+
+		state := "clean"
+		if dirty, err := git.IsDirty(wt.Path); err != nil {
+			state = "missing"
+		} else if dirty {
+			state = "dirty"
+		}
+
+		base, created, note := "-", "-", ""
+		if e := store.Get(wt.Path); e != nil {
+			if e.Base != "" {
+				base = e.Base
+			}
+			if !e.Created.IsZero() {
+				created = e.Created.Local().Format("2006-01-02 15:04")
+				if e.Creator != "" {
+					created += " (" + e.Creator + ")"
+				}
+			}
+			note = e.Summary()
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", branch, state, base, created, note)
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,129 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/songtov/wtt/internal/config"
+	"github.com/songtov/wtt/internal/git"
+	"github.com/songtov/wtt/internal/meta"
+	"github.com/songtov/wtt/internal/worktree"
+	"github.com/spf13/cobra"
+)
+
+var (
+	syncStrategy string
+	syncNoFetch  bool
+)
+
+func init() {
+	syncCmd.Flags().StringVarP(&syncStrategy, "strategy", "s", "", "How to update branches: rebase or merge (default: sync_strategy from .wtt.toml)")
+	syncCmd.Flags().BoolVar(&syncNoFetch, "no-fetch", false, "Skip fetching from remotes before syncing")
+	_ = syncCmd.RegisterFlagCompletionFunc("strategy", cobra.FixedCompletions([]string{"rebase", "merge"}, cobra.ShellCompDirectiveNoFileComp))
+}
+
+var syncCmd = &cobra.Command{
+	Use:   "sync",
+	Short: "Update every worktree branch against its base",
+	Long: `Fetch once, then rebase or merge each worktree's branch onto its base.
+The base is the ref recorded when the worktree was created, falling back to
+the branch's upstream and then to the main worktree's branch; a local base
+branch is followed through its upstream (e.g. main → origin/main). Worktrees with
+uncommitted changes are skipped, and a rebase or merge that hits conflicts is
+aborted so the worktree is left as it was.`,
+	Args: cobra.NoArgs,
+	RunE: runSync,
+}
+
+func runSync(_ *cobra.Command, _ []string) error {
+	repoRoot, err := repoRootWithFallback()
+	if err != nil {
+		return err
+	}
+	autoRegisterRepo(repoRoot)
+
+	cfg, err := config.Load(repoRoot, filepath.Base(repoRoot))
+	if err != nil {
+		return fmt.Errorf("loading config: %w", err)
+	}
+	strategy := cfg.SyncStrategy
+	if syncStrategy != "" {
+		strategy = syncStrategy
+	}
+	if strategy != "rebase" && strategy != "merge" {
+		return fmt.Errorf("invalid sync strategy %q (want rebase or merge)", strategy)
+	}
+
+	worktrees, err := git.ListWorktreesIn(repoRoot)
+	if err != nil {
+		return fmt.Errorf("listing worktrees: %w", err)
+	}
+
+	if !syncNoFetch {
+		fmt.Fprintln(os.Stderr, "Fetching...")
+		if err := git.Fetch(repoRoot); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		}
+	}
+
+	if len(worktrees) == 0 {
+		return fmt.Errorf("no worktrees found")
+	}
+	mainBranch := strings.TrimPrefix(worktrees[0].Branch, "refs/heads/")
+	store, err := meta.Load(repoRoot)
+	if err != nil {
+		return fmt.Errorf("loading metadata: %w", err)
+	}
+
+	conflicts, failures := 0, 0
+	for _, wt := range worktrees {
+		if wt.Branch == "" {
+			continue // detached HEAD — nothing to sync
+		}
+		branch := strings.TrimPrefix(wt.Branch, "refs/heads/")
+
+		// Prefer the recorded base, then the upstream, then the main branch
+		var base string
+		if e := store.Get(wt.Path); e != nil {
+			base = e.Base
+		}
+		if base == "" {
+			base = git.Upstream(wt.Path)
+		}
+		if base == "" && !wt.IsMain {
+			base = mainBranch
+		}
+		// A local base branch (e.g. recorded by an older wtt) is followed
+		// through its upstream, which the fetch just updated
+		if upstream := git.BranchUpstream(repoRoot, base); upstream != "" && base != branch {
+			base = upstream
+		}
+
+		status, err := worktree.Sync(wt.Path, base, strategy)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "  %-16s %s: %v\n", status, branch, err)
+			failures++
+			continue
+		}
+		if status == worktree.SyncConflicted {
+			conflicts++
+		}
+		if base != "" {
+			fmt.Fprintf(os.Stderr, "  %-16s %s (onto %s)\n", status, branch, base)
+		} else {
+			fmt.Fprintf(os.Stderr, "  %-16s %s\n", status, branch)
+		}
+	}
+
+	switch {
+	case conflicts > 0 && failures > 0:
+		return fmt.Errorf("%d worktree(s) had conflicts and were left unchanged, %d failed to sync", conflicts, failures)
+	case conflicts > 0:
+		return fmt.Errorf("%d worktree(s) had conflicts and were left unchanged", conflicts)
+	case failures > 0:
+		return fmt.Errorf("%d worktree(s) failed to sync", failures)
+	}
+	return nil
+}
@@ -0,0 +1,217 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/songtov/wtt/internal/config"
+	"github.com/songtov/wtt/internal/directive"
+	"github.com/songtov/wtt/internal/git"
+	"github.com/songtov/wtt/internal/globalconfig"
+	"github.com/songtov/wtt/internal/meta"
+	"github.com/songtov/wtt/internal/worktree"
+	"github.com/spf13/cobra"
+)
+
+// workspaceUsage describes the --workspace flag of create and remove.
+const workspaceUsage = "Act on every repo of the named workspace (see workspaces in config.toml)"
+
+// workspaceDir returns the directory holding the worktrees of workspace
+// name for branch, and the workspace's repos.
+func workspaceDir(name, branch string) (string, []string, error) {
+	settings, err := globalconfig.LoadSettings()
+	if err != nil {
+		return "", nil, err
+	}
+	names, ok := settings.Workspaces[name]
+	if !ok {
+		return "", nil, fmt.Errorf("no workspace %q; define it under [workspaces] in %s", name, settingsPath())
+	}
+	if len(names) == 0 {
+		return "", nil, fmt.Errorf("workspace %q has no repos", name)
+	}
+
+	known, err := canonicalRepos()
+	if err != nil {
+		return "", nil, err
+	}
+	var repos []string
+	dirs := map[string]string{}
+	for _, n := range names {
+		repo, err := findRepo(known, expandHome(n))
+		if err != nil {
+			return "", nil, fmt.Errorf("workspace %s: %w", name, err)
+		}
+		// Each repo gets a directory named after it in the workspace
+		base := filepath.Base(repo)
+		if other, ok := dirs[base]; ok && other != repo {
+			return "", nil, fmt.Errorf("workspace %s: %s and %s are both named %s", name, other, repo, base)
+		}
+		dirs[base] = repo
+		repos = append(repos, repo)
+	}
+
+	dir := filepath.Join(expandHome(settings.WorkspaceDir), name, git.BranchToPath(branch))
+	return dir, repos, nil
+}
+
+// createWorkspace creates a worktree for branch in every repo of workspace
+// name, side by side under one directory, and moves there. If any repo
+// fails — including its copies and post_create commands — the worktrees (and
+// branches) created so far are rolled back.
+func createWorkspace(name, branch string) error {
+	dir, repos, err := workspaceDir(name, branch)
+	if err != nil {
+		return err
+	}
+	if entries, err := os.ReadDir(dir); err == nil && len(entries) > 0 {
+		return fmt.Errorf("%s already exists", dir)
+	}
+
+	var created []workspaceWorktree
+	for _, repo := range repos {
+		wt := workspaceWorktree{repo: repo, path: filepath.Join(dir, filepath.Base(repo)), newBranch: !git.BranchExists(repo, branch)}
+		fmt.Fprintf(os.Stderr, "==> %s\n", filepath.Base(repo))
+		cfg, err := config.Load(repo, filepath.Base(repo))
+		if err == nil {
+			err = addWorktreeAt(repo, branch, createBase, wt.path)
+		}
+		if err == nil {
+			created = append(created, wt)
+			err = setUpWorktree(repo, cfg, wt.path, true)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Creating the worktree in %s failed; rolling back the workspace\n", filepath.Base(repo))
+			rollbackWorkspace(created, dir, branch)
+			return fmt.Errorf("%s: %w", filepath.Base(repo), err)
+		}
+	}
+
+	fmt.Fprintf(os.Stderr, "Created workspace %s for %q in %s\n", name, branch, dir)
+	return directive.Cd(dir)
+}
+
+// workspaceWorktree is a worktree created by createWorkspace.
+type workspaceWorktree struct {
+	repo, path string
+	// newBranch records that the branch didn't exist before, so rolling
+	// back deletes it too.
+	newBranch bool
+}
+
+// rollbackWorkspace removes the worktrees created so far, newest first,
+// along with the branches they created and the workspace directory.
+func rollbackWorkspace(created []workspaceWorktree, dir, branch string) {
+	for i := len(created) - 1; i >= 0; i-- {
+		wt := created[i]
+		if err := worktree.Remove(wt.repo, wt.path, true); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+			continue
+		}
+		_ = meta.Update(wt.repo, func(store *meta.Store) error {
+			store.Delete(wt.path)
+			return nil
+		})
+		if wt.newBranch {
+			if err := git.DeleteBranch(wt.repo, branch); err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+			}
+		}
+		fmt.Fprintf(os.Stderr, "Rolled back %s\n", filepath.Base(wt.repo))
+	}
+	removeEmptyWorkspaceDir(dir)
+}
+
+// removeWorkspace removes the worktrees for branch in every repo of
+// workspace name, after one confirmation for all of them.
+func removeWorkspace(name, branch string) error {
+	dir, repos, err := workspaceDir(name, branch)
+	if err != nil {
+		return err
+	}
+
+	var targets []workspaceWorktree
+	for _, repo := range repos {
+		path := workspaceWorktreeIn(repo, dir, branch)
+		if path == "" {
+			fmt.Fprintf(os.Stderr, "Warning: %s has no worktree for %q in the workspace\n", filepath.Base(repo), branch)
+			continue
+		}
+		targets = append(targets, workspaceWorktree{repo: repo, path: path})
+	}
+	if len(targets) == 0 {
+		return fmt.Errorf("workspace %s has no worktrees for %q", name, branch)
+	}
+
+	if !forceRemove && !confirm(fmt.Sprintf("Remove %d worktrees of workspace %s for %q? [y/N] ", len(targets), name, branch)) {
+		fmt.Fprintln(os.Stderr, "Aborted.")
+		return nil
+	}
+	failed := 0
+	for _, t := range targets {
+		if err := deleteWorktree(t.repo, t.path, branch, forceRemove); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %s: %v\n", filepath.Base(t.repo), err)
+			failed++
+		}
+	}
+	removeEmptyWorkspaceDir(dir)
+	if failed > 0 {
+		return fmt.Errorf("%d of %d worktrees could not be removed (use --force to discard their changes)", failed, len(targets))
+	}
+	return nil
+}
+
+// workspaceWorktreeIn returns the path of repo's worktree for branch inside
+// the workspace directory dir, or "" if there is none.
+func workspaceWorktreeIn(repo, dir, branch string) string {
+	worktrees, err := git.ListWorktreesIn(repo)
+	if err != nil {
+		return ""
+	}
+	dirs := []string{dir}
+	if resolved, err := filepath.EvalSymlinks(dir); err == nil {
+		dirs = append(dirs, resolved)
+	}
+	for _, wt := range worktrees {
+		if wt.IsMain || strings.TrimPrefix(wt.Branch, "refs/heads/") != branch {
+			continue
+		}
+		for _, d := range dirs {
+			if filepath.Dir(wt.Path) == d {
+				return wt.Path
+			}
+		}
+	}
+	return ""
+}
+
+// removeEmptyWorkspaceDir deletes the branch directory of a workspace and
+// the workspace's own directory if they are left empty.
+func removeEmptyWorkspaceDir(dir string) {
+	// os.Remove leaves non-empty directories alone
+	_ = os.Remove(dir)
+	_ = os.Remove(filepath.Dir(dir))
+}
+
+// settingsPath returns the path of config.toml, for messages.
+func settingsPath() string {
+	dir, err := globalconfig.Dir()
+	if err != nil {
+		return "config.toml"
+	}
+	return filepath.Join(dir, "config.toml")
+}
+
+// completeWorkspaces completes the workspace names defined in config.toml.
+func completeWorkspaces(_ *cobra.Command, _ []string, _ string) ([]string, cobra.ShellCompDirective) {
+	settings, _ := globalconfig.LoadSettings()
+	var names []string
+	for name, repos := range settings.Workspaces {
+		names = append(names, name+"\t"+strings.Join(repos, ", "))
+	}
+	sort.Strings(names)
+	return names, cobra.ShellCompDirectiveNoFileComp
+}
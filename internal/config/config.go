@@ -1,9 +1,12 @@
 package config
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 
 	"github.com/BurntSushi/toml"
 )
@@ -17,20 +20,27 @@ type Config struct {
 	CopyDirs     []string `toml:"copy_dirs"`
 	SymlinkFiles []string `toml:"symlink_files"`
 	PostCreate   []string `toml:"post_create"`
+	SyncStrategy string   `toml:"sync_strategy"`
+	CreateOnMiss bool     `toml:"create_on_miss"`
+	KeepSubdir   bool     `toml:"keep_subdir"`
+	// Env is exported into the shell on every navigation into a worktree.
+	// Values may use {path}, {branch} and {repo} placeholders.
+	Env map[string]string `toml:"env"`
+	// SourceFiles are sourced by the shell after navigating, relative to the
+	// worktree; missing files are skipped.
+	SourceFiles []string `toml:"source_files"`
 }
 
 // Load reads .wtt.toml from repoRoot and merges with defaults.
 func Load(repoRoot, repoName string) (*Config, error) {
 	cfg := defaults(repoName)
 
-	path := filepath.Join(repoRoot, configFile)
-	if _, err := os.Stat(path); os.IsNotExist(err) {
-		return cfg, nil
+	fileCfg, err := LoadFile(repoRoot)
+	if err != nil {
+		return nil, err
 	}
-
-	var fileCfg Config
-	if _, err := toml.DecodeFile(path, &fileCfg); err != nil {
-		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	if fileCfg == nil {
+		return cfg, nil
 	}
 
 	// Merge: non-zero file values override defaults
@@ -49,10 +59,67 @@ func Load(repoRoot, repoName string) (*Config, error) {
 	if len(fileCfg.PostCreate) > 0 {
 		cfg.PostCreate = fileCfg.PostCreate
 	}
+	if fileCfg.SyncStrategy != "" {
+		cfg.SyncStrategy = fileCfg.SyncStrategy
+	}
+	if fileCfg.CreateOnMiss {
+		cfg.CreateOnMiss = true
+	}
+	if fileCfg.KeepSubdir {
+		cfg.KeepSubdir = true
+	}
+	if len(fileCfg.Env) > 0 {
+		cfg.Env = fileCfg.Env
+	}
+	if len(fileCfg.SourceFiles) > 0 {
+		cfg.SourceFiles = fileCfg.SourceFiles
+	}
 
 	return cfg, nil
 }
 
+// Path returns the path of the repo's .wtt.toml.
+func Path(repoRoot string) string {
+	return filepath.Join(repoRoot, configFile)
+}
+
+// LoadFile reads .wtt.toml from repoRoot without applying defaults, so only
+// the keys the file sets are non-zero. Returns nil (no error) if the repo has
+// no .wtt.toml.
+func LoadFile(repoRoot string) (*Config, error) {
+	path := Path(repoRoot)
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return nil, nil
+	}
+	var fileCfg Config
+	if _, err := toml.DecodeFile(path, &fileCfg); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	return &fileCfg, nil
+}
+
+// EnvFingerprint identifies the env and source_files settings, so trusting
+// them can be tied to what they were when the user reviewed them. Returns ""
+// if neither is set.
+func (c *Config) EnvFingerprint() string {
+	if len(c.Env) == 0 && len(c.SourceFiles) == 0 {
+		return ""
+	}
+	keys := make([]string, 0, len(c.Env))
+	for k := range c.Env {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	h := sha256.New()
+	for _, k := range keys {
+		fmt.Fprintf(h, "env\x00%s\x00%s\x00", k, c.Env[k])
+	}
+	for _, f := range c.SourceFiles {
+		fmt.Fprintf(h, "source\x00%s\x00", f)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
 func defaults(repoName string) *Config {
 	return &Config{
 		WorktreeDir:  fmt.Sprintf("../%s-worktrees", repoName),
@@ -60,5 +127,6 @@ func defaults(repoName string) *Config {
 		CopyDirs:     []string{},
 		SymlinkFiles: []string{},
 		PostCreate:   []string{},
+		SyncStrategy: "rebase",
 	}
 }
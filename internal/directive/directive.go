@@ -0,0 +1,74 @@
+// Package directive tells the wtt shell wrapper what to do once wtt-bin
+// exits. A child process can't change its parent shell's directory or
+// environment, so the wrapper passes a temp file in WTT_DIRECTIVE_FILE and
+// applies the directives wtt-bin writes there, one per line:
+//
+//	cd <path>
+//	export <KEY>=<value>
+//	source <file>
+//
+// This keeps stdout free for normal output.
+package directive
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// EnvVar names the environment variable holding the directive file path.
+const EnvVar = "WTT_DIRECTIVE_FILE"
+
+var validKey = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// Active reports whether wtt-bin was started by a shell wrapper that applies
+// directives.
+func Active() bool {
+	return os.Getenv(EnvVar) != ""
+}
+
+// Cd asks the shell to change into path. Without a wrapper, the path is
+// printed on stdout instead so that cd "$(wtt-bin …)" keeps working.
+func Cd(path string) error {
+	if !Active() {
+		fmt.Println(path)
+		return nil
+	}
+	return write("cd", path)
+}
+
+// Export asks the shell to set the environment variable key to value. It is
+// a no-op without a wrapper.
+func Export(key, value string) error {
+	if !validKey.MatchString(key) {
+		return fmt.Errorf("invalid environment variable name %q", key)
+	}
+	if !Active() {
+		return nil
+	}
+	return write("export", key+"="+value)
+}
+
+// Source asks the shell to source file. It is a no-op without a wrapper.
+func Source(file string) error {
+	if !Active() {
+		return nil
+	}
+	return write("source", file)
+}
+
+func write(verb, arg string) error {
+	if strings.ContainsAny(arg, "\n\r") {
+		return fmt.Errorf("%s argument contains a newline: %q", verb, arg)
+	}
+	f, err := os.OpenFile(os.Getenv(EnvVar), os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o600)
+	if err != nil {
+		return fmt.Errorf("opening directive file: %w", err)
+	}
+	if _, err := fmt.Fprintf(f, "%s %s\n", verb, arg); err != nil {
+		f.Close()
+		return fmt.Errorf("writing directive file: %w", err)
+	}
+	return f.Close()
+}
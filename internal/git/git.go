@@ -14,6 +14,11 @@ type Worktree struct {
 	Head   string
 	Branch string
 	IsMain bool
+	// Prunable is git's reason for considering the worktree prunable (e.g.
+	// its directory is gone); empty otherwise. Locked worktrees are never
+	// pruned.
+	Prunable string
+	Locked   bool
 }
 
 // RepoRoot returns the absolute path of the repository root.
@@ -78,6 +83,13 @@ func parseWorktrees(raw string) []Worktree {
 			current.Head = strings.TrimPrefix(line, "HEAD ")
 		} else if strings.HasPrefix(line, "branch ") {
 			current.Branch = strings.TrimPrefix(line, "branch ")
+		} else if line == "prunable" || strings.HasPrefix(line, "prunable ") {
+			current.Prunable = strings.TrimSpace(strings.TrimPrefix(line, "prunable"))
+			if current.Prunable == "" {
+				current.Prunable = "prunable"
+			}
+		} else if line == "locked" || strings.HasPrefix(line, "locked ") {
+			current.Locked = true
 		}
 	}
 	if current.Path != "" {
@@ -131,3 +143,209 @@ func ListWorktreesIn(repoRoot string) ([]Worktree, error) {
 	}
 	return parseWorktrees(string(out)), nil
 }
+
+// Fetch runs `git fetch --all --prune` for the repo at repoRoot.
+func Fetch(repoRoot string) error {
+	out, err := exec.Command("git", "-C", repoRoot, "fetch", "--all", "--prune", "--quiet").CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("git fetch: %w\n%s", err, out)
+	}
+	return nil
+}
+
+// IsDirty reports whether the worktree at dir has uncommitted changes to
+// tracked files. Untracked files are ignored.
+func IsDirty(dir string) (bool, error) {
+	out, err := exec.Command("git", "-C", dir, "status", "--porcelain", "--untracked-files=no").Output()
+	if err != nil {
+		return false, fmt.Errorf("git status: %w", err)
+	}
+	return strings.TrimSpace(string(out)) != "", nil
+}
+
+// Upstream returns the upstream ref (e.g. "origin/main") of the branch
+// checked out in dir, or "" if it has none.
+func Upstream(dir string) string {
+	out, err := exec.Command("git", "-C", dir, "rev-parse", "--abbrev-ref", "--symbolic-full-name", "@{upstream}").Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+}
+
+// BranchUpstream returns the upstream ref (e.g. "origin/main") of the local
+// branch in the repo at repoRoot, or "" if it isn't a local branch or has no
+// upstream.
+func BranchUpstream(repoRoot, branch string) string {
+	if !BranchExists(repoRoot, branch) {
+		return ""
+	}
+	out, err := exec.Command("git", "-C", repoRoot, "rev-parse", "--abbrev-ref", "--symbolic-full-name", branch+"@{upstream}").Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+}
+
+// IsAncestor reports whether ancestor is reachable from ref in the repo at dir.
+func IsAncestor(dir, ancestor, ref string) bool {
+	return exec.Command("git", "-C", dir, "merge-base", "--is-ancestor", ancestor, ref).Run() == nil
+}
+
+// CommonDir returns the absolute path of the git directory shared by all
+// worktrees of the repo at dir (usually <main-root>/.git).
+func CommonDir(dir string) (string, error) {
+	out, err := exec.Command("git", "-C", dir, "rev-parse", "--git-common-dir").Output()
+	if err != nil {
+		return "", fmt.Errorf("not a git repository: %s", dir)
+	}
+	common := strings.TrimSpace(string(out))
+	if !filepath.IsAbs(common) {
+		common = filepath.Join(dir, common)
+	}
+	return filepath.Clean(common), nil
+}
+
+// CurrentBranch returns the short name of the branch checked out in dir, or
+// the commit hash when HEAD is detached.
+func CurrentBranch(dir string) (string, error) {
+	out, err := exec.Command("git", "-C", dir, "rev-parse", "--abbrev-ref", "HEAD").Output()
+	if err != nil {
+		return "", fmt.Errorf("git rev-parse: %w", err)
+	}
+	branch := strings.TrimSpace(string(out))
+	if branch != "HEAD" {
+		return branch, nil
+	}
+	out, err = exec.Command("git", "-C", dir, "rev-parse", "HEAD").Output()
+	if err != nil {
+		return "", fmt.Errorf("git rev-parse: %w", err)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// UserName returns the configured git user.name for the repo at dir, or ""
+// if none is set.
+func UserName(dir string) string {
+	out, err := exec.Command("git", "-C", dir, "config", "user.name").Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+}
+
+// BranchExists reports whether a local branch with the given name exists in
+// the repo at repoRoot.
+func BranchExists(repoRoot, branch string) bool {
+	return exec.Command("git", "-C", repoRoot, "show-ref", "--verify", "--quiet", "refs/heads/"+branch).Run() == nil
+}
+
+// Prune removes the administrative files of worktrees whose directories
+// are gone.
+func Prune(repoRoot string) error {
+	out, err := exec.Command("git", "-C", repoRoot, "worktree", "prune").CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("git worktree prune: %w\n%s", err, out)
+	}
+	return nil
+}
+
+// Version returns the installed git's major and minor version and its full
+// version string (e.g. "2.43.0").
+func Version() (major, minor int, version string, err error) {
+	out, err := exec.Command("git", "--version").Output()
+	if err != nil {
+		return 0, 0, "", fmt.Errorf("git --version: %w", err)
+	}
+	version = strings.TrimPrefix(strings.TrimSpace(string(out)), "git version ")
+	if _, err := fmt.Sscanf(version, "%d.%d", &major, &minor); err != nil {
+		return 0, 0, version, fmt.Errorf("parsing git version %q: %w", version, err)
+	}
+	return major, minor, version, nil
+}
+
+// DeleteBranch force-deletes the local branch in the repo at repoRoot.
+func DeleteBranch(repoRoot, branch string) error {
+	out, err := exec.Command("git", "-C", repoRoot, "branch", "-D", branch).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("git branch -D: %w\n%s", err, out)
+	}
+	return nil
+}
+
+// RemoteBranch returns the remote-tracking ref (e.g. "origin/feature/login")
+// for branch, or "" if no remote has it. When several remotes do, "origin"
+// is preferred.
+func RemoteBranch(repoRoot, branch string) string {
+	out, err := exec.Command("git", "-C", repoRoot, "for-each-ref", "--format=%(refname:short)", "refs/remotes").Output()
+	if err != nil {
+		return ""
+	}
+	var found string
+	scanner := bufio.NewScanner(strings.NewReader(string(out)))
+	for scanner.Scan() {
+		ref := scanner.Text()
+		remote, name, ok := strings.Cut(ref, "/")
+		if !ok || name != branch {
+			continue
+		}
+		if remote == "origin" {
+			return ref
+		}
+		if found == "" {
+			found = ref
+		}
+	}
+	return found
+}
+
+// AheadBehind returns how many commits HEAD in dir is ahead of and behind base.
+func AheadBehind(dir, base string) (ahead, behind int, err error) {
+	out, err := exec.Command("git", "-C", dir, "rev-list", "--left-right", "--count", "HEAD..."+base).Output()
+	if err != nil {
+		return 0, 0, fmt.Errorf("git rev-list: %w", err)
+	}
+	if _, err := fmt.Sscan(string(out), &ahead, &behind); err != nil {
+		return 0, 0, fmt.Errorf("parsing git rev-list output %q: %w", out, err)
+	}
+	return ahead, behind, nil
+}
+
+// StatusShort returns `git status --short` for the worktree at dir.
+func StatusShort(dir string) (string, error) {
+	out, err := exec.Command("git", "-C", dir, "-c", "color.status=always", "status", "--short").Output()
+	if err != nil {
+		return "", fmt.Errorf("git status: %w", err)
+	}
+	return string(out), nil
+}
+
+// RecentCommits returns the last n commits of HEAD in dir, one per line.
+func RecentCommits(dir string, n int) (string, error) {
+	out, err := exec.Command("git", "-C", dir, "log", "--color=always", "--oneline", "--decorate", fmt.Sprintf("-%d", n)).Output()
+	if err != nil {
+		return "", fmt.Errorf("git log: %w", err)
+	}
+	return string(out), nil
+}
+
+// ListRefs returns the short names of the refs under the given prefixes
+// (e.g. "refs/heads"), or of all branches, remote-tracking branches and tags
+// when none are given. Symbolic remote HEADs are left out.
+func ListRefs(repoRoot string, prefixes ...string) ([]string, error) {
+	if len(prefixes) == 0 {
+		prefixes = []string{"refs/heads", "refs/remotes", "refs/tags"}
+	}
+	args := append([]string{"-C", repoRoot, "for-each-ref", "--format=%(refname:short)"}, prefixes...)
+	out, err := exec.Command("git", args...).Output()
+	if err != nil {
+		return nil, fmt.Errorf("git for-each-ref: %w", err)
+	}
+	var refs []string
+	for _, ref := range strings.Split(strings.TrimSpace(string(out)), "\n") {
+		if ref != "" && !strings.HasSuffix(ref, "/HEAD") {
+			refs = append(refs, ref)
+		}
+	}
+	return refs, nil
+}
@@ -0,0 +1,126 @@
+package git
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Location describes where a directory sits in a git repository. It is
+// worked out from the .git entries on disk without running git, for callers
+// such as the prompt segment that must stay fast.
+type Location struct {
+	// Worktree is the root of the worktree containing the directory.
+	Worktree string
+	// DotGit is the .git directory or file found at the worktree root.
+	DotGit string
+	// GitDir is the worktree's own git dir: DotGit itself for the main
+	// worktree, <common-dir>/worktrees/<name> for linked ones.
+	GitDir string
+	// CommonDir is the git dir shared by all worktrees of the repository.
+	CommonDir string
+	// MainRoot is the root of the main worktree, as "git worktree list"
+	// reports first.
+	MainRoot string
+}
+
+// Locate walks up from dir to the nearest .git entry and resolves it,
+// following "gitdir:" files and commondir links.
+func Locate(dir string) (*Location, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+	for d := dir; ; {
+		dotGit := filepath.Join(d, ".git")
+		if info, err := os.Stat(dotGit); err == nil {
+			if loc, err := locateAt(d, dotGit, info); err == nil {
+				return loc, nil
+			}
+		}
+		parent := filepath.Dir(d)
+		if parent == d {
+			return nil, fmt.Errorf("not inside a git repository: %s", dir)
+		}
+		d = parent
+	}
+}
+
+func locateAt(worktree, dotGit string, info os.FileInfo) (*Location, error) {
+	loc := &Location{Worktree: worktree, DotGit: dotGit}
+
+	if info.IsDir() {
+		if _, err := os.Stat(filepath.Join(dotGit, "HEAD")); err != nil {
+			return nil, fmt.Errorf("%s is not a git directory", dotGit)
+		}
+		loc.GitDir, loc.CommonDir, loc.MainRoot = dotGit, dotGit, worktree
+		return loc, nil
+	}
+
+	// A linked worktree or submodule: .git is a file pointing at the git dir
+	gitDir, err := GitFileTarget(dotGit)
+	if err != nil {
+		return nil, err
+	}
+	loc.GitDir = gitDir
+
+	common, err := os.ReadFile(filepath.Join(loc.GitDir, "commondir"))
+	if err != nil {
+		// No commondir: a submodule, which is its own main worktree
+		loc.CommonDir, loc.MainRoot = loc.GitDir, worktree
+		return loc, nil
+	}
+	loc.CommonDir = resolveRelative(loc.GitDir, strings.TrimSpace(string(common)))
+	if filepath.Base(loc.CommonDir) == ".git" {
+		loc.MainRoot = filepath.Dir(loc.CommonDir)
+	} else {
+		loc.MainRoot = loc.CommonDir // worktrees of a bare repository
+	}
+	return loc, nil
+}
+
+// GitFileTarget returns the git dir named by the "gitdir:" line of the .git
+// file dotGit, resolved against the directory holding it. The git dir may no
+// longer exist.
+func GitFileTarget(dotGit string) (string, error) {
+	data, err := os.ReadFile(dotGit)
+	if err != nil {
+		return "", err
+	}
+	gitDir, ok := strings.CutPrefix(strings.TrimSpace(string(data)), "gitdir:")
+	if !ok {
+		return "", fmt.Errorf("%s: missing gitdir line", dotGit)
+	}
+	return resolveRelative(filepath.Dir(dotGit), strings.TrimSpace(gitDir)), nil
+}
+
+func resolveRelative(base, path string) string {
+	if filepath.IsAbs(path) {
+		return filepath.Clean(path)
+	}
+	return filepath.Join(base, path)
+}
+
+// Branch returns the branch checked out in the located worktree, read from
+// its HEAD file, or the abbreviated commit when HEAD is detached.
+func (l *Location) Branch() string {
+	data, err := os.ReadFile(filepath.Join(l.GitDir, "HEAD"))
+	if err != nil {
+		return ""
+	}
+	head := strings.TrimSpace(string(data))
+	if ref, ok := strings.CutPrefix(head, "ref: "); ok {
+		return strings.TrimPrefix(ref, "refs/heads/")
+	}
+	if len(head) > 7 {
+		head = head[:7]
+	}
+	return head
+}
+
+// IsLinked reports whether the located worktree is a linked worktree rather
+// than the main one.
+func (l *Location) IsLinked() bool {
+	return l.Worktree != l.MainRoot
+}
@@ -1,135 +1,174 @@
 package globalconfig
 
 import (
-	"bufio"
 	"os"
 	"path/filepath"
-	"strings"
 )
 
-func configDir() (string, error) {
+// HomeEnvVar overrides the directory holding wtt's global state and
+// config.toml.
+const HomeEnvVar = "WTT_HOME"
+
+// Dir returns wtt's config directory: $WTT_HOME if set, otherwise
+// $XDG_CONFIG_HOME/wtt, falling back to ~/.config/wtt.
+func Dir() (string, error) {
+	if dir := os.Getenv(HomeEnvVar); dir != "" {
+		return dir, nil
+	}
+	if xdg := os.Getenv("XDG_CONFIG_HOME"); filepath.IsAbs(xdg) {
+		return filepath.Join(xdg, "wtt"), nil
+	}
 	home, err := os.UserHomeDir()
 	if err != nil {
 		return "", err
 	}
-	dir := filepath.Join(home, ".config", "wtt")
+	return filepath.Join(home, ".config", "wtt"), nil
+}
+
+// configDir returns Dir, creating it if needed.
+func configDir() (string, error) {
+	dir, err := Dir()
+	if err != nil {
+		return "", err
+	}
 	if err := os.MkdirAll(dir, 0755); err != nil {
 		return "", err
 	}
 	return dir, nil
 }
 
-// GetCurrentRepo returns the path of the currently selected repo context.
-// Returns an empty string (no error) if no context has been set yet.
+// SessionEnvVar holds the repo context of the current shell session. The
+// shell wrapper sets it on "wtt repo use"; while it is unset, the global
+// context applies.
+const SessionEnvVar = "WTT_CONTEXT"
+
+// GetCurrentRepo returns the path of the active repo context: the session's
+// if one is set (an empty session value means "none"), otherwise the global
+// one. Returns an empty string (no error) if no context has been set yet.
 func GetCurrentRepo() (string, error) {
-	dir, err := configDir()
-	if err != nil {
-		return "", err
+	if repo, ok := SessionRepo(); ok {
+		return repo, nil
 	}
-	data, err := os.ReadFile(filepath.Join(dir, "current_repo"))
+	return GetGlobalRepo()
+}
+
+// SessionPreviousEnvVar holds the session's context before its last switch,
+// for "wtt repo -".
+const SessionPreviousEnvVar = "WTT_PREVIOUS_CONTEXT"
+
+// PreviousRepo returns the context that was active before the last switch:
+// the session's if it has switched, otherwise the global one's.
+func PreviousRepo() (string, error) {
+	if repo, ok := os.LookupEnv(SessionPreviousEnvVar); ok {
+		return repo, nil
+	}
+	return GetPreviousRepo()
+}
+
+// SessionRepo returns the repo context of the current shell session and
+// whether one is set.
+func SessionRepo() (string, bool) {
+	return os.LookupEnv(SessionEnvVar)
+}
+
+// GetGlobalRepo returns the repo context shared by all shell sessions.
+// Returns an empty string (no error) if none has been set.
+func GetGlobalRepo() (string, error) {
+	s, err := LoadState()
 	if err != nil {
-		if os.IsNotExist(err) {
-			return "", nil
-		}
 		return "", err
 	}
-	return strings.TrimSpace(string(data)), nil
+	return s.CurrentRepo, nil
 }
 
-// SetCurrentRepo saves the given repo path as the active context.
-func SetCurrentRepo(repoPath string) error {
-	dir, err := configDir()
+// SetGlobalRepo saves the given repo path as the context for all sessions,
+// remembering the one it replaces for "wtt repo -".
+func SetGlobalRepo(repoPath string) error {
+	return UpdateState(func(s *State) error {
+		if s.CurrentRepo != repoPath && s.CurrentRepo != "" {
+			s.PreviousRepo = s.CurrentRepo
+		}
+		s.CurrentRepo = repoPath
+		return nil
+	})
+}
+
+// ClearGlobalRepo clears the global context.
+func ClearGlobalRepo() error {
+	return SetGlobalRepo("")
+}
+
+// GetPreviousRepo returns the global context that was active before the
+// last global switch, for "wtt repo -".
+func GetPreviousRepo() (string, error) {
+	s, err := LoadState()
 	if err != nil {
-		return err
+		return "", err
 	}
-	return os.WriteFile(filepath.Join(dir, "current_repo"), []byte(repoPath+"\n"), 0644)
+	return s.PreviousRepo, nil
 }
 
 // GetKnownRepos returns all registered repo paths.
 func GetKnownRepos() ([]string, error) {
-	dir, err := configDir()
+	s, err := LoadState()
 	if err != nil {
 		return nil, err
 	}
-	data, err := os.ReadFile(filepath.Join(dir, "repos"))
-	if err != nil {
-		if os.IsNotExist(err) {
-			return nil, nil
-		}
-		return nil, err
-	}
-	var repos []string
-	scanner := bufio.NewScanner(strings.NewReader(string(data)))
-	for scanner.Scan() {
-		line := strings.TrimSpace(scanner.Text())
-		if line != "" {
-			repos = append(repos, line)
-		}
-	}
-	return repos, nil
+	return s.RepoPaths(), nil
 }
 
-// SetKnownRepos overwrites the repos list with the given slice.
-func SetKnownRepos(repos []string) error {
-	dir, err := configDir()
-	if err != nil {
-		return err
-	}
-	var sb strings.Builder
-	for _, r := range repos {
-		sb.WriteString(r + "\n")
+// RegisterRepo adds a repo path to the known repos list. It is idempotent.
+func RegisterRepo(repoPath string) error {
+	// Most calls find the repo already known; skip the lock for those
+	if s, err := LoadState(); err == nil {
+		for _, r := range s.Repos {
+			if r.Path == repoPath {
+				return nil
+			}
+		}
 	}
-	return os.WriteFile(filepath.Join(dir, "repos"), []byte(sb.String()), 0644)
+	return UpdateState(func(s *State) error {
+		s.AddRepo(repoPath)
+		return nil
+	})
 }
 
 // RemoveRepo removes a repo path from the known repos list.
 func RemoveRepo(repoPath string) error {
-	repos, err := GetKnownRepos()
-	if err != nil {
-		return err
-	}
-	var filtered []string
-	for _, r := range repos {
-		if r != repoPath {
-			filtered = append(filtered, r)
-		}
-	}
-	return SetKnownRepos(filtered)
+	return UpdateState(func(s *State) error {
+		s.RemoveRepo(repoPath)
+		return nil
+	})
 }
 
-// ClearCurrentRepo removes the saved current-repo context file.
-func ClearCurrentRepo() error {
-	dir, err := configDir()
+// GetAliases returns the repo aliases, mapping alias name to repo path.
+func GetAliases() (map[string]string, error) {
+	s, err := LoadState()
 	if err != nil {
-		return err
+		return nil, err
 	}
-	err = os.Remove(filepath.Join(dir, "current_repo"))
-	if os.IsNotExist(err) {
-		return nil
+	aliases := map[string]string{}
+	for name, path := range s.Aliases {
+		aliases[name] = path
 	}
-	return err
+	return aliases, nil
 }
 
-// RegisterRepo adds a repo path to the known repos list. It is idempotent.
-func RegisterRepo(repoPath string) error {
-	repos, err := GetKnownRepos()
-	if err != nil {
-		return err
-	}
-	for _, r := range repos {
-		if r == repoPath {
-			return nil // already registered
+// SetAlias points the alias name at repoPath.
+func SetAlias(name, repoPath string) error {
+	return UpdateState(func(s *State) error {
+		if s.Aliases == nil {
+			s.Aliases = map[string]string{}
 		}
-	}
-	dir, err := configDir()
-	if err != nil {
-		return err
-	}
-	f, err := os.OpenFile(filepath.Join(dir, "repos"), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
-	if err != nil {
-		return err
-	}
-	defer f.Close()
-	_, err = f.WriteString(repoPath + "\n")
-	return err
+		s.Aliases[name] = repoPath
+		return nil
+	})
+}
+
+// DeleteAlias removes the alias name.
+func DeleteAlias(name string) error {
+	return UpdateState(func(s *State) error {
+		delete(s.Aliases, name)
+		return nil
+	})
 }
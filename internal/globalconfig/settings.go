@@ -0,0 +1,68 @@
+package globalconfig
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/BurntSushi/toml"
+)
+
+const settingsFile = "config.toml"
+
+// DefaultPreviewCommand renders the worktree preview pane. {path} is replaced
+// with the worktree path of the highlighted entry.
+const DefaultPreviewCommand = "wtt-bin preview {path}"
+
+// Settings holds user-wide preferences from ~/.config/wtt/config.toml.
+// Unlike .wtt.toml these apply to every repo.
+type Settings struct {
+	// PreviewCommand is run to fill the picker preview pane; "" disables it.
+	PreviewCommand string `toml:"preview_command"`
+	// Picker selects the interactive picker backend: "auto" (fzf, then
+	// skim, then the built-in picker), "fzf", "sk", "peco", "gum",
+	// "builtin", or "command" to run PickerCommand.
+	Picker string `toml:"picker"`
+	// PickerCommand is a shell command that reads one choice per line on
+	// stdin and prints the selected line(s), used when Picker is "command".
+	PickerCommand string `toml:"picker_command"`
+	// PickerHeight, PickerLayout and PickerColor are passed to backends that
+	// support them (e.g. fzf's --height, --layout and --color).
+	PickerHeight string `toml:"picker_height"`
+	PickerLayout string `toml:"picker_layout"`
+	PickerColor  string `toml:"picker_color"`
+	// PickerArgs are extra arguments appended verbatim to the backend command.
+	PickerArgs []string `toml:"picker_args"`
+	// Workspaces maps a workspace name to the repos (names, aliases or
+	// paths) that "wtt create --workspace" creates a worktree in.
+	Workspaces map[string][]string `toml:"workspaces"`
+	// WorkspaceDir is the parent of workspace worktrees, laid out as
+	// <workspace_dir>/<workspace>/<branch>/<repo>. A leading ~/ is expanded.
+	WorkspaceDir string `toml:"workspace_dir"`
+}
+
+// DefaultWorkspaceDir is where workspace worktrees go unless workspace_dir
+// says otherwise.
+const DefaultWorkspaceDir = "~/wtt-workspaces"
+
+// LoadSettings reads config.toml and fills in defaults for unset keys. A
+// missing file yields the defaults.
+func LoadSettings() (*Settings, error) {
+	s := &Settings{PreviewCommand: DefaultPreviewCommand, Picker: "auto", WorkspaceDir: DefaultWorkspaceDir}
+
+	dir, err := configDir()
+	if err != nil {
+		return s, err
+	}
+	path := filepath.Join(dir, settingsFile)
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return s, nil
+	}
+
+	// Decoding into the defaults keeps them for keys the file doesn't set,
+	// while an explicit preview_command = "" still turns the preview off.
+	if _, err := toml.DecodeFile(path, s); err != nil {
+		return s, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	return s, nil
+}
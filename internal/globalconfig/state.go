@@ -0,0 +1,240 @@
+package globalconfig
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/songtov/wtt/internal/fileutil"
+)
+
+const (
+	stateFile = "state.json"
+	lockFile  = "state.lock"
+
+	// stateVersion is the format version written to state.json. A wtt that
+	// finds a newer version reads the file but refuses to overwrite it.
+	stateVersion = 1
+)
+
+// State is wtt's global state: the known repos, their aliases and the
+// global repo context. It lives in state.json in the config directory and
+// is changed only through UpdateState, so concurrent wtt processes (prompt
+// hooks, scripts, several shells) never lose each other's writes.
+type State struct {
+	Version int `json:"version"`
+	// Repos are the known repositories in the order they were registered.
+	Repos []Repo `json:"repos"`
+	// Aliases maps alias names to repo paths.
+	Aliases map[string]string `json:"aliases,omitempty"`
+	// CurrentRepo is the repo context shared by all sessions; PreviousRepo
+	// the one before the last global switch, for "wtt repo -".
+	CurrentRepo  string `json:"current_repo,omitempty"`
+	PreviousRepo string `json:"previous_repo,omitempty"`
+}
+
+// Repo is a known repository and its metadata.
+type Repo struct {
+	Path string `json:"path"`
+	// Added is when the repo was registered (or migrated from the old
+	// repos file).
+	Added time.Time `json:"added"`
+	// KeepMissing records that the user chose to keep the repo while its
+	// path was missing, so "wtt repo list" doesn't ask about it again.
+	KeepMissing bool `json:"keep_missing,omitempty"`
+	// TrustedEnv is the fingerprint of the .wtt.toml env and source_files
+	// settings the user allowed wtt to apply ("wtt repo trust"); EnvWarned
+	// the one they were last told is being ignored.
+	TrustedEnv string `json:"trusted_env,omitempty"`
+	EnvWarned  string `json:"env_warned,omitempty"`
+	// TrustedFiles are the hashes of the source_files contents the user
+	// reviewed when trusting; a file whose contents hash to anything else
+	// is not sourced.
+	TrustedFiles []string `json:"trusted_files,omitempty"`
+}
+
+// RepoPaths returns the paths of the known repos.
+func (s *State) RepoPaths() []string {
+	paths := make([]string, len(s.Repos))
+	for i, r := range s.Repos {
+		paths[i] = r.Path
+	}
+	return paths
+}
+
+// AddRepo registers path if it isn't known yet and reports whether it was
+// added.
+func (s *State) AddRepo(path string) bool {
+	for _, r := range s.Repos {
+		if r.Path == path {
+			return false
+		}
+	}
+	s.Repos = append(s.Repos, Repo{Path: path, Added: time.Now().UTC().Truncate(time.Second)})
+	return true
+}
+
+// RemoveRepo drops path from the known repos and reports whether it was
+// known. Aliases and contexts pointing at it are left alone.
+func (s *State) RemoveRepo(path string) bool {
+	for i, r := range s.Repos {
+		if r.Path == path {
+			s.Repos = append(s.Repos[:i], s.Repos[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// LoadState reads the global state. It needs no lock: state.json is only
+// ever replaced whole. On first use the state is migrated from the
+// newline files older versions of wtt kept (repos, current_repo,
+// previous_repo, aliases).
+func LoadState() (*State, error) {
+	dir, err := configDir()
+	if err != nil {
+		return nil, err
+	}
+	s, err := readState(dir)
+	if err == nil || !os.IsNotExist(err) {
+		return s, err
+	}
+	if !hasLegacyState(dir) {
+		return &State{Version: stateVersion}, nil
+	}
+	// Migrate under the lock, so only one process does it
+	if err := UpdateState(func(*State) error { return nil }); err != nil {
+		return nil, err
+	}
+	return readState(dir)
+}
+
+// UpdateState applies fn to the global state under an exclusive lock and
+// saves the result atomically. Nothing is written if fn returns an error
+// or leaves the state unchanged.
+func UpdateState(fn func(*State) error) error {
+	dir, err := configDir()
+	if err != nil {
+		return err
+	}
+	unlock, err := fileutil.Lock(filepath.Join(dir, lockFile))
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	s, err := readState(dir)
+	migrated := false
+	if os.IsNotExist(err) {
+		s, err = readLegacyState(dir)
+		migrated = true
+	}
+	if err != nil {
+		return err
+	}
+	if s.Version > stateVersion {
+		return fmt.Errorf("%s was written by a newer version of wtt (format %d); upgrade wtt", filepath.Join(dir, stateFile), s.Version)
+	}
+
+	before, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+	if err := fn(s); err != nil {
+		return err
+	}
+	if after, err := json.Marshal(s); err == nil && !migrated && bytes.Equal(before, after) {
+		return nil
+	}
+	s.Version = stateVersion
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	return fileutil.WriteAtomic(filepath.Join(dir, stateFile), append(data, '\n'), 0644)
+}
+
+func readState(dir string) (*State, error) {
+	data, err := os.ReadFile(filepath.Join(dir, stateFile))
+	if err != nil {
+		return nil, err
+	}
+	s := &State{}
+	if err := json.Unmarshal(data, s); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", filepath.Join(dir, stateFile), err)
+	}
+	return s, nil
+}
+
+// legacyFiles are the state files of older versions of wtt. They are left
+// in place after migration but no longer read.
+var legacyFiles = []string{"repos", "current_repo", "previous_repo", "aliases"}
+
+func hasLegacyState(dir string) bool {
+	for _, name := range legacyFiles {
+		if _, err := os.Stat(filepath.Join(dir, name)); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// readLegacyState builds the state from the old newline files; missing
+// files are simply empty.
+func readLegacyState(dir string) (*State, error) {
+	s := &State{Version: stateVersion}
+	read := func(name string) ([]string, error) {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("migrating %s: %w", name, err)
+		}
+		var lines []string
+		scanner := bufio.NewScanner(bytes.NewReader(data))
+		for scanner.Scan() {
+			if line := strings.TrimSpace(scanner.Text()); line != "" {
+				lines = append(lines, line)
+			}
+		}
+		return lines, nil
+	}
+
+	repos, err := read("repos")
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range repos {
+		s.AddRepo(r)
+	}
+	if lines, err := read("current_repo"); err != nil {
+		return nil, err
+	} else if len(lines) > 0 {
+		s.CurrentRepo = lines[0]
+	}
+	if lines, err := read("previous_repo"); err != nil {
+		return nil, err
+	} else if len(lines) > 0 {
+		s.PreviousRepo = lines[0]
+	}
+	aliases, err := read("aliases")
+	if err != nil {
+		return nil, err
+	}
+	for _, line := range aliases {
+		name, path, ok := strings.Cut(line, "\t")
+		if ok && name != "" && path != "" {
+			if s.Aliases == nil {
+				s.Aliases = map[string]string{}
+			}
+			s.Aliases[strings.TrimSpace(name)] = strings.TrimSpace(path)
+		}
+	}
+	return s, nil
+}
@@ -0,0 +1,157 @@
+package history
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/songtov/wtt/internal/fileutil"
+	"github.com/songtov/wtt/internal/meta"
+)
+
+const (
+	historyFile = "history"
+	// maxEntries bounds the history file; older visits are dropped on write.
+	maxEntries = 1000
+)
+
+// Visit is a single navigation to a worktree.
+type Visit struct {
+	Time time.Time
+	Path string
+}
+
+func file(repoRoot string) (string, error) {
+	dir, err := meta.Dir(repoRoot)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, historyFile), nil
+}
+
+// Load returns the recorded visits for the repo at repoRoot, oldest first.
+func Load(repoRoot string) ([]Visit, error) {
+	path, err := file(repoRoot)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var visits []Visit
+	scanner := bufio.NewScanner(strings.NewReader(string(data)))
+	for scanner.Scan() {
+		ts, p, ok := strings.Cut(scanner.Text(), "\t")
+		if !ok {
+			continue
+		}
+		sec, err := strconv.ParseInt(ts, 10, 64)
+		if err != nil {
+			continue
+		}
+		visits = append(visits, Visit{Time: time.Unix(sec, 0), Path: p})
+	}
+	return visits, nil
+}
+
+// Record appends a visit to each of worktreePaths, in order, to the repo's
+// history. The file is rewritten atomically under a lock, so navigations in
+// concurrent shells don't lose each other's visits.
+func Record(repoRoot string, worktreePaths ...string) error {
+	path, err := file(repoRoot)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	unlock, err := fileutil.Lock(path + ".lock")
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	visits, err := Load(repoRoot)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	for _, p := range worktreePaths {
+		visits = append(visits, Visit{Time: now, Path: p})
+	}
+	if len(visits) > maxEntries {
+		visits = visits[len(visits)-maxEntries:]
+	}
+
+	var sb strings.Builder
+	for _, v := range visits {
+		fmt.Fprintf(&sb, "%d\t%s\n", v.Time.Unix(), v.Path)
+	}
+	return fileutil.WriteAtomic(path, []byte(sb.String()), 0o644)
+}
+
+// Last returns the path of the most recent visit, or "" if there is none.
+func Last(visits []Visit) string {
+	if len(visits) == 0 {
+		return ""
+	}
+	return visits[len(visits)-1].Path
+}
+
+// Recent returns the distinct visited paths, most recently visited first.
+func Recent(visits []Visit) []string {
+	seen := map[string]bool{}
+	var paths []string
+	for i := len(visits) - 1; i >= 0; i-- {
+		p := visits[i].Path
+		if !seen[p] {
+			seen[p] = true
+			paths = append(paths, p)
+		}
+	}
+	return paths
+}
+
+// Frecency scores each visited path by how often and how recently it was
+// visited, the way zoxide ranks directories: every visit counts, weighted by
+// its age. Higher is better.
+func Frecency(visits []Visit) map[string]float64 {
+	now := time.Now()
+	scores := map[string]float64{}
+	for _, v := range visits {
+		age := now.Sub(v.Time)
+		var w float64
+		switch {
+		case age < time.Hour:
+			w = 4
+		case age < 24*time.Hour:
+			w = 2
+		case age < 7*24*time.Hour:
+			w = 0.5
+		default:
+			w = 0.25
+		}
+		scores[v.Path] += w
+	}
+	return scores
+}
+
+// LastVisited returns the time of the most recent visit to each path.
+func LastVisited(visits []Visit) map[string]time.Time {
+	last := map[string]time.Time{}
+	for _, v := range visits {
+		if v.Time.After(last[v.Path]) {
+			last[v.Path] = v.Time
+		}
+	}
+	return last
+}
@@ -0,0 +1,134 @@
+package meta
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/songtov/wtt/internal/fileutil"
+	"github.com/songtov/wtt/internal/git"
+)
+
+const (
+	metaFile = "worktrees.toml"
+	lockFile = "worktrees.lock"
+)
+
+// Entry holds what wtt remembers about a single worktree.
+type Entry struct {
+	Branch  string    `toml:"branch"`
+	Base    string    `toml:"base,omitempty"`
+	Created time.Time `toml:"created,omitempty"`
+	Creator string    `toml:"creator,omitempty"`
+	Command string    `toml:"command,omitempty"`
+	Note    string    `toml:"note,omitempty"`
+	Labels  []string  `toml:"labels,omitempty"`
+}
+
+// Summary returns the note followed by any labels, e.g. "fix login [bug, p1]".
+func (e *Entry) Summary() string {
+	s := e.Note
+	if len(e.Labels) > 0 {
+		if s != "" {
+			s += " "
+		}
+		s += "[" + strings.Join(e.Labels, ", ") + "]"
+	}
+	return s
+}
+
+// Store is the per-repo metadata file, kept at <git-common-dir>/wtt/worktrees.toml
+// so it is shared by every worktree of the repo. Entries are keyed by the
+// absolute worktree path.
+type Store struct {
+	Worktrees map[string]*Entry `toml:"worktrees"`
+
+	path string
+}
+
+// Dir returns the wtt state directory inside the repo's git common dir.
+func Dir(repoRoot string) (string, error) {
+	common, err := git.CommonDir(repoRoot)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(common, "wtt"), nil
+}
+
+// Load reads the metadata store for the repo at repoRoot. A missing file
+// yields an empty store.
+func Load(repoRoot string) (*Store, error) {
+	dir, err := Dir(repoRoot)
+	if err != nil {
+		return nil, err
+	}
+	return load(dir)
+}
+
+// LoadCommon is like Load for a repo whose git common dir is already known,
+// and runs no git commands.
+func LoadCommon(commonDir string) (*Store, error) {
+	return load(filepath.Join(commonDir, "wtt"))
+}
+
+func load(dir string) (*Store, error) {
+	s := &Store{path: filepath.Join(dir, metaFile)}
+	if _, err := toml.DecodeFile(s.path, s); err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("parsing %s: %w", s.path, err)
+	}
+	if s.Worktrees == nil {
+		s.Worktrees = map[string]*Entry{}
+	}
+	return s, nil
+}
+
+// Get returns the entry for worktreePath, or nil if none is recorded.
+func (s *Store) Get(worktreePath string) *Entry {
+	return s.Worktrees[worktreePath]
+}
+
+// Set records e for worktreePath, replacing any existing entry.
+func (s *Store) Set(worktreePath string, e *Entry) {
+	s.Worktrees[worktreePath] = e
+}
+
+// Delete forgets worktreePath.
+func (s *Store) Delete(worktreePath string) {
+	delete(s.Worktrees, worktreePath)
+}
+
+// Update applies fn to the metadata store of the repo at repoRoot under an
+// exclusive lock and saves the result atomically, so concurrent wtt
+// processes don't drop each other's entries and readers never see a partly
+// written file. Nothing is written if fn returns an error.
+func Update(repoRoot string, fn func(*Store) error) error {
+	dir, err := Dir(repoRoot)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	unlock, err := fileutil.Lock(filepath.Join(dir, lockFile))
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	s, err := load(dir)
+	if err != nil {
+		return err
+	}
+	if err := fn(s); err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	if err := toml.NewEncoder(&buf).Encode(s); err != nil {
+		return err
+	}
+	return fileutil.WriteAtomic(s.path, buf.Bytes(), 0o644)
+}
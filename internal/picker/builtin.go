@@ -0,0 +1,23 @@
+package picker
+
+import "github.com/songtov/wtt/internal/tui"
+
+// builtinBackend is wtt's own terminal picker (see package tui).
+type builtinBackend struct{}
+
+func (builtinBackend) choose(items []item, opts chooseOptions) (*chooseResult, error) {
+	labels := make([]string, len(items))
+	for i, it := range items {
+		labels[i] = it.label
+	}
+	res, err := tui.Run(labels, tui.Options{
+		Query:  opts.query,
+		Header: opts.header,
+		Multi:  opts.multi,
+		Expect: opts.expect,
+	})
+	if err != nil || res == nil {
+		return nil, err
+	}
+	return &chooseResult{indices: res.Indices, key: res.Key, query: res.Query}, nil
+}
@@ -0,0 +1,108 @@
+package picker
+
+import (
+	"fmt"
+	"os/exec"
+
+	"github.com/songtov/wtt/internal/globalconfig"
+	"github.com/songtov/wtt/internal/tui"
+)
+
+// item is one line of a picker.
+type item struct {
+	label string // shown and filtered on; may contain ANSI colors
+	path  string // worktree path for the preview pane, if any
+	// numbered replaces label in the numbered fallback, if set
+	numbered string
+}
+
+type chooseOptions struct {
+	title     string // numbered fallback heading
+	query     string
+	header    string
+	preview   string
+	multi     bool
+	expect    []string
+	zeroBased bool // number the numbered fallback from 0 instead of 1
+}
+
+type chooseResult struct {
+	indices []int
+	key     string
+	query   string
+}
+
+// backend is an interactive picker program (or wtt's own picker). choose
+// returns the indices of the chosen items, or nil if the user cancelled.
+// Backends that can't honour an option (preview, expect keys, …) ignore it.
+type backend interface {
+	choose(items []item, opts chooseOptions) (*chooseResult, error)
+}
+
+// Backends selectable with "picker" in config.toml.
+var backendNames = []string{"auto", "fzf", "sk", "peco", "gum", "builtin", "command"}
+
+// choose runs the configured picker backend over items.
+func choose(items []item, opts chooseOptions) (*chooseResult, error) {
+	settings, err := globalconfig.LoadSettings()
+	if err != nil {
+		return nil, err
+	}
+	b, err := backendFor(settings)
+	if err != nil {
+		return nil, err
+	}
+	return b.choose(items, opts)
+}
+
+// backendFor resolves the "picker" setting to a backend. "auto" prefers fzf,
+// then skim, then the built-in picker; without a terminal to draw on, every
+// interactive choice degrades to a numbered prompt on stdin.
+func backendFor(s *globalconfig.Settings) (backend, error) {
+	switch s.Picker {
+	case "", "auto":
+		if installed("fzf") {
+			return newFzfBackend("fzf", s), nil
+		}
+		if installed("sk") {
+			return newFzfBackend("sk", s), nil
+		}
+		return builtinOrNumbered(), nil
+	case "fzf", "sk":
+		if !installed(s.Picker) {
+			return nil, fmt.Errorf("picker %q is not installed", s.Picker)
+		}
+		return newFzfBackend(s.Picker, s), nil
+	case "peco":
+		if !installed("peco") {
+			return nil, fmt.Errorf("picker %q is not installed", s.Picker)
+		}
+		return pecoBackend(s), nil
+	case "gum":
+		if !installed("gum") {
+			return nil, fmt.Errorf("picker %q is not installed", s.Picker)
+		}
+		return gumBackend(s), nil
+	case "builtin":
+		return builtinOrNumbered(), nil
+	case "command":
+		if s.PickerCommand == "" {
+			return nil, fmt.Errorf(`picker = "command" requires picker_command in config.toml`)
+		}
+		return commandBackend(s.PickerCommand), nil
+	default:
+		return nil, fmt.Errorf("unknown picker %q (want one of: %v)", s.Picker, backendNames)
+	}
+}
+
+func builtinOrNumbered() backend {
+	if tui.Available() {
+		return builtinBackend{}
+	}
+	return numberedBackend{}
+}
+
+func installed(bin string) bool {
+	_, err := exec.LookPath(bin)
+	return err == nil
+}
@@ -0,0 +1,99 @@
+package picker
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"github.com/songtov/wtt/internal/globalconfig"
+)
+
+// fzfBackend drives fzf or skim (sk), which share the flags wtt relies on:
+// hidden columns, ANSI colors, preview, multi-select and --expect keys.
+type fzfBackend struct {
+	bin  string
+	args []string
+}
+
+func newFzfBackend(bin string, s *globalconfig.Settings) fzfBackend {
+	var args []string
+	if s.PickerHeight != "" {
+		args = append(args, "--height="+s.PickerHeight)
+	}
+	if s.PickerLayout != "" {
+		args = append(args, "--layout="+s.PickerLayout)
+	}
+	if s.PickerColor != "" {
+		args = append(args, "--color="+s.PickerColor)
+	}
+	return fzfBackend{bin: bin, args: append(args, s.PickerArgs...)}
+}
+
+func (b fzfBackend) choose(items []item, opts chooseOptions) (*chooseResult, error) {
+	var input strings.Builder
+	for i, it := range items {
+		fmt.Fprintf(&input, "%d\t%s\t%s\n", i, it.label, it.path)
+	}
+
+	// --print-query and --expect make the picker print the query and the
+	// key pressed on their own lines before the selection.
+	args := []string{"--with-nth=2", "--delimiter=\t", "--ansi", "--print-query"}
+	if opts.query != "" {
+		args = append(args, "--query", opts.query)
+	}
+	if opts.header != "" {
+		args = append(args, "--header", opts.header)
+	}
+	if opts.preview != "" {
+		// {3} is substituted (and quoted) with the hidden path column
+		args = append(args, "--preview", strings.ReplaceAll(opts.preview, "{path}", "{3}"), "--preview-window=right:50%")
+	}
+	if opts.multi {
+		args = append(args, "--multi")
+	}
+	if len(opts.expect) > 0 {
+		args = append(args, "--expect="+strings.Join(opts.expect, ","))
+	}
+	args = append(args, b.args...)
+
+	cmd := exec.Command(b.bin, args...)
+	cmd.Stdin = strings.NewReader(input.String())
+	cmd.Stderr = os.Stderr
+
+	var out bytes.Buffer
+	cmd.Stdout = &out
+
+	if err := cmd.Run(); err != nil {
+		switch cmd.ProcessState.ExitCode() {
+		case 130:
+			return nil, nil // user cancelled
+		case 1:
+			// No match — the query and key are still meaningful
+		default:
+			return nil, fmt.Errorf("%s: %w", b.bin, err)
+		}
+	}
+
+	lines := strings.Split(strings.TrimRight(out.String(), "\n"), "\n")
+	res := &chooseResult{query: lines[0]}
+	lines = lines[1:]
+	if len(opts.expect) > 0 && len(lines) > 0 {
+		res.key = lines[0]
+		lines = lines[1:]
+	}
+	for _, line := range lines {
+		if line == "" {
+			continue
+		}
+		idxStr, _, _ := strings.Cut(line, "\t")
+		idx, err := strconv.Atoi(idxStr)
+		if err != nil || idx < 0 || idx >= len(items) {
+			return nil, fmt.Errorf("unexpected %s output: %q", b.bin, line)
+		}
+		res.indices = append(res.indices, idx)
+	}
+	return res, nil
+}
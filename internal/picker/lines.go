@@ -0,0 +1,135 @@
+package picker
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"github.com/songtov/wtt/internal/globalconfig"
+	"github.com/songtov/wtt/internal/tui"
+)
+
+// lineBackend runs a program that reads one choice per line on stdin and
+// prints the selected line(s) on stdout — peco, gum filter, or any custom
+// command. Lines are matched back to items by their text, so labels are sent
+// without colors and made unique.
+type lineBackend struct {
+	name    string
+	command func(opts chooseOptions) *exec.Cmd
+}
+
+func pecoBackend(s *globalconfig.Settings) lineBackend {
+	return lineBackend{name: "peco", command: func(opts chooseOptions) *exec.Cmd {
+		var args []string
+		if opts.query != "" {
+			args = append(args, "--query", opts.query)
+		}
+		if layout := pecoLayout(s.PickerLayout); layout != "" {
+			args = append(args, "--layout", layout)
+		}
+		return exec.Command("peco", append(args, s.PickerArgs...)...)
+	}}
+}
+
+// pecoLayout maps picker_layout onto peco's --layout, which only knows
+// top-down and bottom-up. fzf's layouts are translated; anything else is
+// dropped rather than make peco exit with an error.
+func pecoLayout(layout string) string {
+	switch layout {
+	case "top-down", "bottom-up":
+		return layout
+	case "reverse":
+		return "top-down"
+	case "default":
+		return "bottom-up"
+	}
+	return ""
+}
+
+func gumBackend(s *globalconfig.Settings) lineBackend {
+	return lineBackend{name: "gum", command: func(opts chooseOptions) *exec.Cmd {
+		args := []string{"filter"}
+		if opts.query != "" {
+			args = append(args, "--value", opts.query)
+		}
+		if opts.header != "" {
+			args = append(args, "--header", opts.header)
+		}
+		if opts.multi {
+			args = append(args, "--no-limit")
+		}
+		// gum's --height counts lines; a percentage has no equivalent
+		if _, err := strconv.Atoi(s.PickerHeight); err == nil {
+			args = append(args, "--height", s.PickerHeight)
+		}
+		return exec.Command("gum", append(args, s.PickerArgs...)...)
+	}}
+}
+
+// commandBackend runs a user-supplied shell command. The initial query and
+// whether several lines may be picked are exposed as $WTT_PICKER_QUERY and
+// $WTT_PICKER_MULTI.
+func commandBackend(command string) lineBackend {
+	return lineBackend{name: "picker_command", command: func(opts chooseOptions) *exec.Cmd {
+		cmd := exec.Command("sh", "-c", command)
+		multi := "0"
+		if opts.multi {
+			multi = "1"
+		}
+		cmd.Env = append(os.Environ(), "WTT_PICKER_QUERY="+opts.query, "WTT_PICKER_MULTI="+multi)
+		return cmd
+	}}
+}
+
+func (b lineBackend) choose(items []item, opts chooseOptions) (*chooseResult, error) {
+	byLine := map[string]int{}
+	var input strings.Builder
+	for i, it := range items {
+		line := tui.StripANSI(it.label)
+		for n := 2; ; n++ {
+			if _, dup := byLine[line]; !dup {
+				break
+			}
+			line = fmt.Sprintf("%s #%d", tui.StripANSI(it.label), n)
+		}
+		byLine[line] = i
+		input.WriteString(line + "\n")
+	}
+
+	cmd := b.command(opts)
+	cmd.Stdin = strings.NewReader(input.String())
+	cmd.Stderr = os.Stderr
+	var out bytes.Buffer
+	cmd.Stdout = &out
+
+	if err := cmd.Run(); err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return nil, nil // cancelled or nothing chosen
+		}
+		return nil, fmt.Errorf("%s: %w", b.name, err)
+	}
+
+	res := &chooseResult{}
+	for _, line := range strings.Split(out.String(), "\n") {
+		if line = strings.TrimRight(line, "\r"); line == "" {
+			continue
+		}
+		idx, ok := byLine[line]
+		if !ok {
+			return nil, fmt.Errorf("%s printed %q, which is not one of the choices", b.name, line)
+		}
+		res.indices = append(res.indices, idx)
+		if !opts.multi {
+			break
+		}
+	}
+	if len(res.indices) == 0 {
+		return nil, nil
+	}
+	return res, nil
+}
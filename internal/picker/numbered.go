@@ -0,0 +1,56 @@
+package picker
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// numberedBackend prints the items as a numbered list on stderr and reads
+// the choice from stdin. It is the fallback when there is no terminal to
+// draw an interactive picker on. In multi mode several numbers may be
+// given, separated by spaces or commas.
+type numberedBackend struct{}
+
+func (numberedBackend) choose(items []item, opts chooseOptions) (*chooseResult, error) {
+	first := 1
+	if opts.zeroBased {
+		first = 0
+	}
+
+	fmt.Fprintln(os.Stderr, opts.title+":")
+	for i, it := range items {
+		label := it.label
+		if it.numbered != "" {
+			label = it.numbered
+		}
+		if it.path != "" {
+			label += "  " + it.path
+		}
+		fmt.Fprintf(os.Stderr, "  [%d] %s\n", i+first, label)
+	}
+	fmt.Fprint(os.Stderr, "Enter number: ")
+
+	scanner := bufio.NewScanner(os.Stdin)
+	scanner.Scan()
+	text := strings.TrimSpace(scanner.Text())
+	if text == "" {
+		return nil, nil
+	}
+
+	fields := strings.FieldsFunc(text, func(r rune) bool { return r == ' ' || r == ',' })
+	if !opts.multi && len(fields) > 1 {
+		return nil, fmt.Errorf("invalid selection %q", text)
+	}
+	res := &chooseResult{}
+	for _, f := range fields {
+		n, err := strconv.Atoi(f)
+		if err != nil || n < first || n >= len(items)+first {
+			return nil, fmt.Errorf("invalid selection %q", f)
+		}
+		res.indices = append(res.indices, n-first)
+	}
+	return res, nil
+}
@@ -0,0 +1,233 @@
+package picker
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/songtov/wtt/internal/git"
+)
+
+// Options customise a worktree picker.
+type Options struct {
+	// Query pre-fills the picker's filter.
+	Query string
+	// Details holds extra text (such as a note) shown after a worktree's
+	// branch, keyed by worktree path.
+	Details map[string]string
+	// Preview is a command rendering the preview pane; {path} is replaced
+	// with the highlighted worktree's path. Empty disables the preview.
+	Preview string
+	// Actions enables the ctrl-d/n/o/y action keys (see Pick).
+	Actions bool
+	// Repos holds the repo name shown before each worktree's branch, keyed
+	// by worktree path, for pickers listing worktrees of several repos.
+	Repos map[string]string
+}
+
+// Action is what the user asked to do with the picked worktree.
+type Action string
+
+const (
+	ActionNavigate Action = "navigate"
+	ActionRemove   Action = "remove"
+	ActionCreate   Action = "create"
+	ActionEdit     Action = "edit"
+	ActionCopy     Action = "copy"
+)
+
+// actionKeys maps the keys enabled by Options.Actions to their action.
+var actionKeys = []struct {
+	key    string
+	action Action
+	help   string
+}{
+	{"ctrl-d", ActionRemove, "remove"},
+	{"ctrl-n", ActionCreate, "create"},
+	{"ctrl-o", ActionEdit, "edit"},
+	{"ctrl-y", ActionCopy, "copy"},
+}
+
+// Result is the outcome of a worktree picker.
+type Result struct {
+	// Worktree is the highlighted worktree; nil for ActionCreate when
+	// nothing matched the query.
+	Worktree *git.Worktree
+	Action   Action
+	// Query is the filter text typed when the picker closed.
+	Query string
+}
+
+// Select presents a list of worktrees for selection.
+// It uses fzf or the built-in picker when available; otherwise a numbered list.
+// Returns the selected worktree path, or "" if the user cancelled.
+func Select(worktrees []git.Worktree, opts Options) (string, error) {
+	wt, err := SelectWorktree(worktrees, opts)
+	if err != nil || wt == nil {
+		return "", err
+	}
+	return wt.Path, nil
+}
+
+// SelectWorktree presents a list of worktrees for selection and returns the
+// selected Worktree, or nil if the user cancelled.
+func SelectWorktree(worktrees []git.Worktree, opts Options) (*git.Worktree, error) {
+	opts.Actions = false
+	res, err := Pick(worktrees, opts)
+	if err != nil || res == nil {
+		return nil, err
+	}
+	return res.Worktree, nil
+}
+
+// SelectWorktrees is like SelectWorktree but lets the user mark several
+// worktrees (tab in fzf and the built-in picker). Returns nil if cancelled.
+func SelectWorktrees(worktrees []git.Worktree, opts Options) ([]git.Worktree, error) {
+	if len(worktrees) == 0 {
+		return nil, fmt.Errorf("no worktrees found")
+	}
+	res, err := choose(worktreeItems(worktrees, opts), chooseOptions{
+		title:   "Select worktrees",
+		query:   opts.Query,
+		preview: opts.Preview,
+		multi:   true,
+	})
+	if err != nil || res == nil {
+		return nil, err
+	}
+	var selected []git.Worktree
+	for _, i := range res.indices {
+		selected = append(selected, worktrees[i])
+	}
+	return selected, nil
+}
+
+// Pick presents a list of worktrees and returns the chosen worktree together
+// with the action picked for it, or nil if the user cancelled. Action keys
+// other than enter are only offered when opts.Actions is set and an
+// interactive picker is available.
+func Pick(worktrees []git.Worktree, opts Options) (*Result, error) {
+	if len(worktrees) == 0 {
+		return nil, fmt.Errorf("no worktrees found")
+	}
+
+	co := chooseOptions{
+		title:   "Select a worktree",
+		query:   opts.Query,
+		preview: opts.Preview,
+	}
+	if opts.Query != "" {
+		co.title = fmt.Sprintf("Select a worktree matching %q", opts.Query)
+	}
+	if opts.Actions {
+		var help []string
+		for _, k := range actionKeys {
+			co.expect = append(co.expect, k.key)
+			help = append(help, k.key+": "+k.help)
+		}
+		co.header = "enter: go · " + strings.Join(help, " · ")
+	}
+
+	res, err := choose(worktreeItems(worktrees, opts), co)
+	if err != nil || res == nil {
+		return nil, err
+	}
+
+	out := &Result{Action: ActionNavigate, Query: res.query}
+	for _, k := range actionKeys {
+		if k.key == res.key {
+			out.Action = k.action
+		}
+	}
+	if len(res.indices) == 0 {
+		if out.Action == ActionCreate {
+			return out, nil
+		}
+		return nil, nil // nothing matched; treat like a cancel
+	}
+	out.Worktree = &worktrees[res.indices[0]]
+	return out, nil
+}
+
+func worktreeItems(worktrees []git.Worktree, opts Options) []item {
+	width := 0
+	for _, wt := range worktrees {
+		width = max(width, len(opts.Repos[wt.Path]))
+	}
+	items := make([]item, len(worktrees))
+	for i, wt := range worktrees {
+		label := worktreeLabel(wt, opts)
+		if width > 0 {
+			label = fmt.Sprintf("\033[36m%-*s\033[0m %s", width, opts.Repos[wt.Path], label)
+		}
+		items[i] = item{label: label, path: wt.Path}
+	}
+	return items
+}
+
+// worktreeLabel renders a worktree's picker line: a marker for the main
+// worktree, the short branch name and any details in dim text.
+func worktreeLabel(wt git.Worktree, opts Options) string {
+	branch := wt.Branch
+	if branch == "" {
+		branch = "(detached)"
+	}
+	branch = strings.TrimPrefix(branch, "refs/heads/")
+	if wt.IsMain {
+		branch = "\033[32m●\033[0m " + branch
+	} else {
+		branch = "  " + branch
+	}
+	if d := opts.Details[wt.Path]; d != "" {
+		branch += "  \033[2m" + d + "\033[0m"
+	}
+	return branch
+}
+
+// SelectRepo presents a list of repo paths for selection via fzf (or the
+// built-in or numbered fallback), each labelled with its entry in details.
+// Returns the selected absolute repo path, or "" if cancelled.
+func SelectRepo(repos []string, details map[string]string) (string, error) {
+	if len(repos) == 0 {
+		return "", fmt.Errorf("no repos registered yet; run wtt commands inside a git repo first")
+	}
+	res, err := choose(repoItems(repos, details), chooseOptions{title: "Select a repo"})
+	if err != nil || res == nil || len(res.indices) == 0 {
+		return "", err
+	}
+	return repos[res.indices[0]], nil
+}
+
+// SelectRepoWithNone is like SelectRepo but prepends a "None" option that
+// represents clearing the current repo context.
+// Returns (path, noneSelected, err). noneSelected is true when the user
+// explicitly chose "(none)"; path is "" and noneSelected is false when cancelled.
+func SelectRepoWithNone(repos []string, details map[string]string) (string, bool, error) {
+	none := item{label: "(none)", numbered: "(none) – clear repo context"}
+	items := append([]item{none}, repoItems(repos, details)...)
+	res, err := choose(items, chooseOptions{title: "Select a repo (0 to clear)", zeroBased: true})
+	if err != nil || res == nil || len(res.indices) == 0 {
+		return "", false, err
+	}
+	if res.indices[0] == 0 {
+		return "", true, nil
+	}
+	return repos[res.indices[0]-1], false, nil
+}
+
+// repoItems labels each repo with its name, its entry in details (keyed by
+// path) if any, and its path.
+func repoItems(repos []string, details map[string]string) []item {
+	items := make([]item, len(repos))
+	for i, p := range repos {
+		name := filepath.Base(p)
+		if d := details[p]; d != "" {
+			name += "  " + d
+		}
+		items[i] = item{
+			label:    name + "  \033[2m" + p + "\033[0m",
+			numbered: name + "  " + p,
+		}
+	}
+	return items
+}
@@ -0,0 +1,124 @@
+// Package promptcache remembers where a directory sits in a git repository so
+// the prompt segment can answer without walking the filesystem. Entries are
+// keyed by the working directory and invalidated when the mtime of the .git
+// entry they were resolved from changes.
+package promptcache
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/songtov/wtt/internal/git"
+)
+
+const (
+	cacheFile = "prompt"
+	// maxEntries bounds the cache; the least recently stored entries go first.
+	maxEntries = 256
+)
+
+type entry struct {
+	cwd   string
+	mtime int64
+	loc   git.Location
+}
+
+func file() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "wtt", cacheFile), nil
+}
+
+// Each line is: cwd, .git mtime, then the Location fields.
+func load() []entry {
+	path, err := file()
+	if err != nil {
+		return nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	var entries []entry
+	scanner := bufio.NewScanner(strings.NewReader(string(data)))
+	for scanner.Scan() {
+		f := strings.Split(scanner.Text(), "\t")
+		if len(f) != 7 {
+			continue
+		}
+		mtime, err := strconv.ParseInt(f[1], 10, 64)
+		if err != nil {
+			continue
+		}
+		entries = append(entries, entry{cwd: f[0], mtime: mtime, loc: git.Location{
+			DotGit: f[2], Worktree: f[3], GitDir: f[4], CommonDir: f[5], MainRoot: f[6],
+		}})
+	}
+	return entries
+}
+
+// Lookup returns the location cached for cwd, if the .git entry it was
+// resolved from is unchanged.
+func Lookup(cwd string) (*git.Location, bool) {
+	for _, e := range load() {
+		if e.cwd != cwd {
+			continue
+		}
+		info, err := os.Stat(e.loc.DotGit)
+		if err != nil || info.ModTime().UnixNano() != e.mtime {
+			return nil, false
+		}
+		return &e.loc, true
+	}
+	return nil, false
+}
+
+// Store caches loc as the location of cwd. Errors are ignored: the cache is
+// only an optimisation.
+func Store(cwd string, loc *git.Location) {
+	info, err := os.Stat(loc.DotGit)
+	if err != nil {
+		return
+	}
+	fields := []string{cwd, "", loc.DotGit, loc.Worktree, loc.GitDir, loc.CommonDir, loc.MainRoot}
+	if strings.ContainsAny(strings.Join(fields, ""), "\t\n") {
+		return
+	}
+
+	entries := []entry{{cwd: cwd, mtime: info.ModTime().UnixNano(), loc: *loc}}
+	for _, e := range load() {
+		if e.cwd != cwd && len(entries) < maxEntries {
+			entries = append(entries, e)
+		}
+	}
+
+	path, err := file()
+	if err != nil {
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return
+	}
+	var sb strings.Builder
+	for _, e := range entries {
+		sb.WriteString(strings.Join([]string{
+			e.cwd, strconv.FormatInt(e.mtime, 10),
+			e.loc.DotGit, e.loc.Worktree, e.loc.GitDir, e.loc.CommonDir, e.loc.MainRoot,
+		}, "\t") + "\n")
+	}
+	// Write to a temp file and rename so concurrent prompts never read a
+	// half-written cache.
+	tmp := path + "." + strconv.Itoa(os.Getpid()) + "." + strconv.FormatInt(time.Now().UnixNano(), 36)
+	if err := os.WriteFile(tmp, []byte(sb.String()), 0o644); err != nil {
+		return
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+	}
+}
@@ -1,21 +1,36 @@
 package shell
 
-import "fmt"
+import (
+	"fmt"
+	"strings"
+)
 
 const zshFunc = `
-# wtt shell wrapper — lets "wtt cd/create/list" change directory
+# wtt shell wrapper — lets "wtt cd/create/list" change directory.
+# wtt-bin writes directives ("cd <path>", "export K=V", "source <file>") to
+# the file named by WTT_DIRECTIVE_FILE; they are applied once it succeeds.
 wtt() {
-  local output
-  output=$(wtt-bin "$@" 2>/dev/tty)
-  local exit_code=$?
-  if [ $exit_code -ne 0 ]; then
-    return $exit_code
+  # Completion requests ("wtt __complete ...") go straight to the binary so
+  # their output reaches the completion script untouched.
+  if [[ "$1" == __complete* ]]; then
+    command wtt-bin "$@"
+    return
   fi
-  if [ -d "$output" ]; then
-    cd "$output" || return 1
-  else
-    echo "$output"
+  local directives exit_code line
+  directives=$(mktemp -t wtt.XXXXXX) || return 1
+  WTT_DIRECTIVE_FILE="$directives" command wtt-bin "$@"
+  exit_code=$?
+  if [ $exit_code -eq 0 ]; then
+    while IFS= read -r line; do
+      case "$line" in
+        "cd "*) cd -- "${line#cd }" || exit_code=1 ;;
+        "export "*) line=${line#export }; export "${line%%=*}=${line#*=}" ;;
+        "source "*) . "${line#source }" ;;
+      esac
+    done < "$directives"
   fi
+  rm -f "$directives"
+  return $exit_code
 }
 
 # ── Prompt integration ───────────────────────────────────────────────────────
@@ -38,6 +53,31 @@ _wtt_precmd() {
   _WTT_PS1="${ctx:+%F{cyan}$'\ue0a0' ${ctx}%f}"
 }
 
+# Async mode (set WTT_PROMPT_ASYNC=1 before sourcing this): the segment is
+# computed in the background and the prompt redrawn via zle -F when it
+# changes, so the prompt never waits on wtt-bin.
+_wtt_async_start() {
+  if (( ${_WTT_ASYNC_FD:-0} )); then
+    zle -F $_WTT_ASYNC_FD 2>/dev/null
+    exec {_WTT_ASYNC_FD}<&-
+  fi
+  exec {_WTT_ASYNC_FD}< <(wtt-bin context 2>/dev/null)
+  zle -F $_WTT_ASYNC_FD _wtt_async_done
+}
+
+_wtt_async_done() {
+  local fd=$1 ctx
+  IFS= read -r -u $fd ctx
+  zle -F $fd
+  exec {fd}<&-
+  _WTT_ASYNC_FD=0
+  local ps1="${ctx:+%F{cyan}$'\ue0a0' ${ctx}%f}"
+  if [[ "$ps1" != "$_WTT_PS1" ]]; then
+    _WTT_PS1=$ps1
+    zle && zle reset-prompt
+  fi
+}
+
 # _wtt_setup runs once on the first prompt render, after all plugins are loaded.
 # By that point we know which prompt framework is active and can wire up correctly.
 _wtt_setup() {
@@ -70,8 +110,13 @@ _wtt_setup() {
 
   # Generic zsh (plain, oh-my-zsh without p10k, etc.):
   # Hook _wtt_precmd and inject _WTT_PS1 into RPROMPT automatically.
-  precmd_functions+=(_wtt_precmd)
-  _wtt_precmd  # populate immediately for the first prompt
+  if [[ -n "$WTT_PROMPT_ASYNC" ]]; then
+    precmd_functions+=(_wtt_async_start)
+    _wtt_async_start
+  else
+    precmd_functions+=(_wtt_precmd)
+    _wtt_precmd  # populate immediately for the first prompt
+  fi
 
   # Prepend our segment to whatever RPROMPT is already set.
   if [[ -n "$RPROMPT" ]]; then
@@ -85,19 +130,31 @@ precmd_functions+=(_wtt_setup)
 `
 
 const bashFunc = `
-# wtt shell wrapper — lets "wtt cd/create/list" change directory
+# wtt shell wrapper — lets "wtt cd/create/list" change directory.
+# wtt-bin writes directives ("cd <path>", "export K=V", "source <file>") to
+# the file named by WTT_DIRECTIVE_FILE; they are applied once it succeeds.
 wtt() {
-  local output
-  output=$(wtt-bin "$@" 2>/dev/tty)
-  local exit_code=$?
-  if [ $exit_code -ne 0 ]; then
-    return $exit_code
+  # Completion requests ("wtt __complete ...") go straight to the binary so
+  # their output reaches the completion script untouched.
+  if [[ "$1" == __complete* ]]; then
+    command wtt-bin "$@"
+    return
   fi
-  if [ -d "$output" ]; then
-    cd "$output" || return 1
-  else
-    echo "$output"
+  local directives exit_code line
+  directives=$(mktemp -t wtt.XXXXXX) || return 1
+  WTT_DIRECTIVE_FILE="$directives" command wtt-bin "$@"
+  exit_code=$?
+  if [ $exit_code -eq 0 ]; then
+    while IFS= read -r line; do
+      case "$line" in
+        "cd "*) cd -- "${line#cd }" || exit_code=1 ;;
+        "export "*) line=${line#export }; export "${line%%=*}=${line#*=}" ;;
+        "source "*) . "${line#source }" ;;
+      esac
+    done < "$directives"
   fi
+  rm -f "$directives"
+  return $exit_code
 }
 
 # ── Prompt integration ───────────────────────────────────────────────────────
@@ -120,18 +177,34 @@ PS1='$(_wtt_update_ps1)${_WTT_PS1}'"$PS1"
 `
 
 const fishFunc = `
-# wtt shell wrapper — lets "wtt cd/create/list" change directory
+# wtt shell wrapper — lets "wtt cd/create/list" change directory.
+# wtt-bin writes directives ("cd <path>", "export K=V", "source <file>") to
+# the file named by WTT_DIRECTIVE_FILE; they are applied once it succeeds.
 function wtt
-  set output (wtt-bin $argv 2>/dev/tty)
-  set exit_code $status
-  if test $exit_code -ne 0
-    return $exit_code
+  # Completion requests ("wtt __complete ...") go straight to the binary so
+  # their output reaches the completion script untouched.
+  if string match -q -- '__complete*' $argv[1]
+    command wtt-bin $argv
+    return
   end
-  if test -d "$output"
-    cd "$output"
-  else
-    echo "$output"
+  set -l directives (mktemp -t wtt.XXXXXX); or return 1
+  env WTT_DIRECTIVE_FILE=$directives wtt-bin $argv
+  set -l exit_code $status
+  if test $exit_code -eq 0
+    while read -l line
+      switch $line
+        case 'cd *'
+          cd (string sub -s 4 -- $line); or set exit_code 1
+        case 'export *'
+          set -l kv (string split -m 1 = -- (string sub -s 8 -- $line))
+          set -gx $kv[1] $kv[2]
+        case 'source *'
+          source (string sub -s 8 -- $line)
+      end
+    end < $directives
   end
+  rm -f $directives
+  return $exit_code
 end
 
 # ── Prompt integration ───────────────────────────────────────────────────────
@@ -158,6 +231,227 @@ if not functions -q fish_right_prompt
 end
 `
 
+const nuFunc = `
+# wtt shell wrapper — lets "wtt cd/create/list" change directory.
+# wtt-bin writes directives ("cd <path>", "export K=V", "source <file>") to
+# the file named by WTT_DIRECTIVE_FILE; they are applied once it succeeds.
+# "source" directives name POSIX shell files and are skipped here.
+def --env --wrapped wtt [...args: string] {
+  if ($args | length) > 0 and ($args | first | str starts-with "__complete") {
+    ^wtt-bin ...$args
+    return
+  }
+  let directives = (mktemp -t wtt.XXXXXX)
+  $env.WTT_DIRECTIVE_FILE = $directives
+  do --ignore-errors { ^wtt-bin ...$args }
+  let exit_code = $env.LAST_EXIT_CODE
+  hide-env WTT_DIRECTIVE_FILE
+  if $exit_code == 0 {
+    for line in (open --raw $directives | lines) {
+      if ($line | str starts-with "cd ") {
+        cd ($line | str substring 3..)
+      } else if ($line | str starts-with "export ") {
+        let kv = ($line | str substring 7.. | split row -n 2 "=")
+        load-env ({} | insert ($kv | first) ($kv | get 1? | default ""))
+      }
+    }
+  }
+  rm -f $directives
+  if $exit_code != 0 {
+    error make {msg: $"wtt-bin exited with ($exit_code)"}
+  }
+}
+
+# ── Prompt integration ───────────────────────────────────────────────────────
+# Automatically prepends the current repo name to the right prompt.
+# If your config sets PROMPT_COMMAND_RIGHT after sourcing this file, call
+# wtt-segment from it instead.
+
+def wtt-segment [] {
+  let ctx = (^wtt-bin context | complete | get stdout | str trim)
+  if ($ctx | is-empty) { "" } else { $"(ansi cyan)\u{e0a0} ($ctx)(ansi reset) " }
+}
+
+let wtt_prev_right_prompt = ($env.PROMPT_COMMAND_RIGHT? | default "")
+$env.PROMPT_COMMAND_RIGHT = {||
+  let prev = if ($wtt_prev_right_prompt | describe | str starts-with "closure") {
+    do $wtt_prev_right_prompt
+  } else {
+    $wtt_prev_right_prompt
+  }
+  $"(wtt-segment)($prev)"
+}
+`
+
+const pwshFunc = `
+# wtt shell wrapper — lets "wtt cd/create/list" change directory.
+# wtt-bin writes directives ("cd <path>", "export K=V", "source <file>") to
+# the file named by WTT_DIRECTIVE_FILE; they are applied once it succeeds.
+# "source" directives name POSIX shell files and are skipped here.
+function global:wtt {
+    # Completion requests ("wtt __complete ...") go straight to the binary so
+    # their output reaches the completion script untouched.
+    if ($args.Count -gt 0 -and "$($args[0])".StartsWith('__complete')) {
+        & wtt-bin @args
+        return
+    }
+    $directives = (New-TemporaryFile).FullName
+    $env:WTT_DIRECTIVE_FILE = $directives
+    try {
+        & wtt-bin @args
+        $exitCode = $LASTEXITCODE
+    } finally {
+        Remove-Item Env:WTT_DIRECTIVE_FILE -ErrorAction SilentlyContinue
+    }
+    if ($exitCode -eq 0) {
+        foreach ($line in Get-Content -LiteralPath $directives) {
+            if ($line.StartsWith('cd ')) {
+                Set-Location -LiteralPath $line.Substring(3)
+            } elseif ($line.StartsWith('export ')) {
+                $kv = $line.Substring(7).Split('=', 2)
+                Set-Item -LiteralPath "Env:$($kv[0])" -Value $kv[1]
+            }
+        }
+    }
+    Remove-Item -LiteralPath $directives -ErrorAction SilentlyContinue
+    $global:LASTEXITCODE = $exitCode
+}
+
+# ── Prompt integration ───────────────────────────────────────────────────────
+# Automatically prepends the current repo name to the prompt by wrapping the
+# existing prompt function. Source this after Starship / oh-my-posh so their
+# prompt is the one wrapped.
+
+function global:wtt_segment {
+    $ctx = wtt-bin context 2>$null
+    if ($ctx) { "$([char]27)[36m$([char]0xE0A0) $ctx$([char]27)[0m " } else { '' }
+}
+
+if (-not $global:_WttOriginalPrompt) {
+    $global:_WttOriginalPrompt = $function:prompt
+    function global:prompt {
+        $exitCode = $global:LASTEXITCODE
+        $segment = wtt_segment
+        $global:LASTEXITCODE = $exitCode
+        $segment + (& $global:_WttOriginalPrompt)
+    }
+}
+`
+
+const elvishFunc = `
+use str
+
+# wtt shell wrapper — lets "wtt cd/create/list" change directory.
+# wtt-bin writes directives ("cd <path>", "export K=V", "source <file>") to
+# the file named by WTT_DIRECTIVE_FILE; they are applied once it succeeds.
+# "source" directives name POSIX shell files and are skipped here.
+fn wtt {|@args|
+  if (and (> (count $args) 0) (str:has-prefix $args[0] __complete)) {
+    e:wtt-bin $@args
+    return
+  }
+  var directives = (e:mktemp -t wtt.XXXXXX)
+  # A failing wtt-bin skips the directives and its exception (with the exit
+  # status) propagates unchanged once the file is cleaned up.
+  try {
+    e:env WTT_DIRECTIVE_FILE=$directives wtt-bin $@args
+    for line [(from-lines < $directives)] {
+      if (str:has-prefix $line 'cd ') {
+        cd $line[3..]
+      } elif (str:has-prefix $line 'export ') {
+        var kv = $line[7..]
+        var i = (str:index $kv =)
+        set-env $kv[..$i] $kv[(+ $i 1)..]
+      }
+    }
+  } finally {
+    e:rm -f $directives
+  }
+}
+edit:add-var wtt~ $wtt~
+
+# ── Prompt integration ───────────────────────────────────────────────────────
+# Automatically prepends the current repo name to the right prompt.
+
+fn wtt-segment {
+  var ctx = ''
+  try {
+    set ctx = (str:trim-space (e:wtt-bin context 2>/dev/null | slurp))
+  } catch {
+  }
+  if (!=s $ctx '') {
+    styled "\ue0a0 "$ctx' ' cyan
+  }
+}
+edit:add-var wtt-segment~ $wtt-segment~
+
+var wtt-prev-rprompt = $edit:rprompt
+set edit:rprompt = {
+  wtt-segment
+  $wtt-prev-rprompt
+}
+`
+
+const xonshFunc = `
+# wtt shell wrapper — lets "wtt cd/create/list" change directory.
+# wtt-bin writes directives ("cd <path>", "export K=V", "source <file>") to
+# the file named by WTT_DIRECTIVE_FILE; they are applied once it succeeds.
+# "source" directives name POSIX shell files and are skipped here.
+import os as _wtt_os
+import subprocess as _wtt_subprocess
+import tempfile as _wtt_tempfile
+from xonsh.dirstack import cd as _wtt_cd
+from xonsh.tools import uncapturable as _wtt_uncapturable
+from xonsh.tools import unthreadable as _wtt_unthreadable
+
+
+@_wtt_uncapturable
+@_wtt_unthreadable
+def _wtt(args):
+    if args and args[0].startswith('__complete'):
+        return _wtt_subprocess.call(['wtt-bin', *args])
+    fd, directives = _wtt_tempfile.mkstemp(prefix='wtt.')
+    _wtt_os.close(fd)
+    try:
+        env = ${...}.detype()
+        env['WTT_DIRECTIVE_FILE'] = directives
+        code = _wtt_subprocess.call(['wtt-bin', *args], env=env)
+        if code == 0:
+            with open(directives) as f:
+                for line in f.read().splitlines():
+                    if line.startswith('cd '):
+                        _wtt_cd([line[3:]])
+                    elif line.startswith('export '):
+                        key, _, value = line[7:].partition('=')
+                        ${...}[key] = value
+        return code
+    finally:
+        _wtt_os.remove(directives)
+
+
+aliases['wtt'] = _wtt
+
+# ── Prompt integration ───────────────────────────────────────────────────────
+# Automatically prepends the current repo name to the right prompt via a
+# {wtt} prompt field, which can also be used in a custom $PROMPT.
+
+
+def _wtt_prompt_field():
+    try:
+        out = _wtt_subprocess.run(['wtt-bin', 'context'], capture_output=True, text=True).stdout
+    except OSError:
+        return None
+    return out.strip() or None
+
+
+$PROMPT_FIELDS['wtt'] = _wtt_prompt_field
+if '{wtt' not in $RIGHT_PROMPT:
+    $RIGHT_PROMPT = '{CYAN}{wtt:\ue0a0 {} }{RESET}' + $RIGHT_PROMPT
+`
+
+// Supported lists the shells InitFunction accepts.
+var Supported = []string{"zsh", "bash", "fish", "nu", "pwsh", "elvish", "xonsh"}
+
 // InitFunction returns the shell function definitions for the given shell.
 // Source the output in your rc file:
 //
@@ -170,7 +464,15 @@ func InitFunction(sh string) (string, error) {
 		return bashFunc, nil
 	case "fish":
 		return fishFunc, nil
+	case "nu", "nushell":
+		return nuFunc, nil
+	case "pwsh", "powershell":
+		return pwshFunc, nil
+	case "elvish":
+		return elvishFunc, nil
+	case "xonsh":
+		return xonshFunc, nil
 	default:
-		return "", fmt.Errorf("unsupported shell %q; supported: zsh, bash, fish", sh)
+		return "", fmt.Errorf("unsupported shell %q; supported: %s", sh, strings.Join(Supported, ", "))
 	}
 }
@@ -0,0 +1,115 @@
+package tui
+
+import (
+	"strings"
+	"unicode"
+)
+
+// fuzzyMatch reports whether every rune of query appears in text in order,
+// ignoring case. It returns a score (higher is better) that rewards
+// consecutive runs and matches at word boundaries, and the rune positions
+// in text that matched, for highlighting.
+func fuzzyMatch(query, text string) (score int, positions []int, ok bool) {
+	if query == "" {
+		return 0, nil, true
+	}
+	q := []rune(strings.ToLower(query))
+	t := []rune(text)
+
+	qi := 0
+	prev := -2
+	for ti, r := range t {
+		if qi == len(q) {
+			break
+		}
+		if unicode.ToLower(r) != q[qi] {
+			continue
+		}
+		score++
+		if ti == prev+1 {
+			score += 3 // consecutive
+		}
+		if ti == 0 || strings.ContainsRune("/-_. ", t[ti-1]) {
+			score += 2 // start of a word
+		}
+		positions = append(positions, ti)
+		prev = ti
+		qi++
+	}
+	if qi < len(q) {
+		return 0, nil, false
+	}
+	// Prefer shorter candidates among equally good matches
+	score = score*100 - len(t)
+	return score, positions, true
+}
+
+// StripANSI removes ANSI escape sequences from s.
+func StripANSI(s string) string {
+	var sb strings.Builder
+	inEsc := false
+	for _, r := range s {
+		switch {
+		case inEsc:
+			if r >= '@' && r <= '~' && r != '[' {
+				inEsc = false
+			}
+		case r == '\033':
+			inEsc = true
+		default:
+			sb.WriteRune(r)
+		}
+	}
+	return sb.String()
+}
+
+// highlight renders label (which may contain ANSI colors) truncated to width
+// visible runes, with the runes at positions emphasised. Positions index the
+// label's visible runes, i.e. StripANSI(label).
+func highlight(label string, positions []int, width int) string {
+	const on = "\033[1;33m"
+
+	hl := map[int]bool{}
+	for _, p := range positions {
+		hl[p] = true
+	}
+
+	var sb strings.Builder
+	var esc strings.Builder
+	lastSGR := "" // color set by the label itself, restored after a highlight
+	inEsc := false
+	visible := 0
+	for _, r := range label {
+		if inEsc {
+			esc.WriteRune(r)
+			if r >= '@' && r <= '~' && r != '[' {
+				inEsc = false
+				seq := esc.String()
+				sb.WriteString(seq)
+				if r == 'm' {
+					lastSGR = seq
+				}
+			}
+			continue
+		}
+		if r == '\033' {
+			inEsc = true
+			esc.Reset()
+			esc.WriteRune(r)
+			continue
+		}
+		if visible >= width {
+			break
+		}
+		if hl[visible] {
+			sb.WriteString(on)
+			sb.WriteRune(r)
+			sb.WriteString("\033[0m" + lastSGR)
+		} else {
+			sb.WriteRune(r)
+		}
+		visible++
+	}
+	sb.WriteString("\033[0m")
+	return sb.String()
+}
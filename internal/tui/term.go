@@ -0,0 +1,117 @@
+package tui
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// terminal is the controlling terminal, switched to raw mode for the
+// lifetime of a picker. Raw mode is toggled with stty so wtt needs no
+// platform-specific termios code.
+type terminal struct {
+	tty   *os.File
+	saved string
+	rows  int
+	cols  int
+}
+
+func openTerminal() (*terminal, error) {
+	tty, err := os.OpenFile("/dev/tty", os.O_RDWR, 0)
+	if err != nil {
+		return nil, fmt.Errorf("opening terminal: %w", err)
+	}
+	t := &terminal{tty: tty}
+
+	saved, err := t.stty("-g")
+	if err != nil {
+		tty.Close()
+		return nil, err
+	}
+	t.saved = strings.TrimSpace(saved)
+
+	if _, err := t.stty("raw", "-echo"); err != nil {
+		tty.Close()
+		return nil, err
+	}
+	t.updateSize()
+
+	// Alternate screen, hidden cursor
+	fmt.Fprint(tty, "\033[?1049h\033[?25l")
+	return t, nil
+}
+
+func (t *terminal) stty(args ...string) (string, error) {
+	cmd := exec.Command("stty", args...)
+	cmd.Stdin = t.tty
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("stty %s: %w", strings.Join(args, " "), err)
+	}
+	return string(out), nil
+}
+
+func (t *terminal) updateSize() {
+	t.rows, t.cols = 24, 80
+	out, err := t.stty("size")
+	if err != nil {
+		return
+	}
+	var rows, cols int
+	if _, err := fmt.Sscan(out, &rows, &cols); err == nil && rows > 0 && cols > 0 {
+		t.rows, t.cols = rows, cols
+	}
+}
+
+func (t *terminal) close() {
+	fmt.Fprint(t.tty, "\033[?25h\033[?1049l")
+	_, _ = t.stty(t.saved)
+	t.tty.Close()
+}
+
+// readKey blocks until the next key press and returns its name: "enter",
+// "esc", "up", "down", "backspace", "tab", "ctrl-a" … "ctrl-z", or the typed
+// text for printable input.
+func (t *terminal) readKey() (string, error) {
+	buf := make([]byte, 64)
+	n, err := t.tty.Read(buf)
+	if err != nil {
+		return "", err
+	}
+	b := buf[:n]
+
+	switch {
+	case len(b) == 1 && b[0] == 27:
+		return "esc", nil
+	case len(b) >= 3 && b[0] == 27 && (b[1] == '[' || b[1] == 'O'):
+		switch b[2] {
+		case 'A':
+			return "up", nil
+		case 'B':
+			return "down", nil
+		case 'C':
+			return "right", nil
+		case 'D':
+			return "left", nil
+		case 'Z':
+			return "btab", nil
+		case '5':
+			return "pgup", nil
+		case '6':
+			return "pgdn", nil
+		}
+		return "", nil // unsupported escape sequence
+	case len(b) == 1 && (b[0] == '\r' || b[0] == '\n'):
+		return "enter", nil
+	case len(b) == 1 && b[0] == '\t':
+		return "tab", nil
+	case len(b) == 1 && (b[0] == 127 || b[0] == 8):
+		return "backspace", nil
+	case len(b) == 1 && b[0] >= 1 && b[0] <= 26:
+		return fmt.Sprintf("ctrl-%c", 'a'+b[0]-1), nil
+	case b[0] < 32:
+		return "", nil // other control bytes
+	}
+	return string(b), nil
+}
@@ -0,0 +1,262 @@
+// Package tui is wtt's built-in interactive picker, used when fzf is not
+// installed or when it is configured as the preferred picker. It draws on
+// /dev/tty, so it works even when stdout is redirected.
+package tui
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// Options customise a picker.
+type Options struct {
+	// Query pre-fills the filter.
+	Query string
+	// Header is shown above the list.
+	Header string
+	// Multi lets tab toggle several items for selection.
+	Multi bool
+	// Expect lists extra keys (e.g. "ctrl-d") that accept the selection
+	// like enter does; the key pressed is reported in Result.Key.
+	Expect []string
+}
+
+// Result is the outcome of a picker.
+type Result struct {
+	// Indices of the chosen items: the highlighted one, or all toggled ones
+	// in multi mode. Empty when nothing matched the query.
+	Indices []int
+	// Key is the Expect key that closed the picker, or "" for enter.
+	Key string
+	// Query is the filter text when the picker closed.
+	Query string
+}
+
+// Available reports whether a terminal is available to draw the picker on.
+func Available() bool {
+	tty, err := os.OpenFile("/dev/tty", os.O_RDWR, 0)
+	if err != nil {
+		return false
+	}
+	tty.Close()
+	return true
+}
+
+type match struct {
+	index     int
+	score     int
+	positions []int
+}
+
+type picker struct {
+	labels   []string // as given, may contain ANSI colors
+	plain    []string // labels without ANSI, used for matching
+	opts     Options
+	query    []rune
+	matches  []match
+	cursor   int // index into matches
+	offset   int // first visible match
+	selected map[int]bool
+}
+
+// Run shows labels in an interactive, fuzzy-filtered list and returns the
+// user's choice, or nil if they cancelled with esc or ctrl-c.
+//
+// Keys: type to filter, up/down (ctrl-p/ctrl-n, ctrl-k/ctrl-j) to move, tab
+// to toggle in multi mode, backspace/ctrl-w/ctrl-u to edit the query, enter
+// to accept.
+func Run(labels []string, opts Options) (*Result, error) {
+	t, err := openTerminal()
+	if err != nil {
+		return nil, err
+	}
+	defer t.close()
+
+	p := &picker{
+		labels:   labels,
+		opts:     opts,
+		query:    []rune(opts.Query),
+		selected: map[int]bool{},
+	}
+	for _, l := range labels {
+		p.plain = append(p.plain, StripANSI(l))
+	}
+	p.filter()
+
+	for {
+		t.updateSize()
+		p.draw(t)
+
+		key, err := t.readKey()
+		if err != nil {
+			return nil, err
+		}
+
+		if p.expects(key) {
+			return p.result(key), nil
+		}
+		switch key {
+		case "enter":
+			return p.result(""), nil
+		case "esc", "ctrl-c", "ctrl-g", "ctrl-q":
+			return nil, nil
+		case "up", "ctrl-p", "ctrl-k", "btab":
+			p.move(-1)
+		case "down", "ctrl-n", "ctrl-j":
+			p.move(1)
+		case "pgup":
+			p.move(-p.height(t))
+		case "pgdn":
+			p.move(p.height(t))
+		case "tab":
+			if p.opts.Multi && len(p.matches) > 0 {
+				idx := p.matches[p.cursor].index
+				p.selected[idx] = !p.selected[idx]
+				p.move(1)
+			}
+		case "backspace", "ctrl-h":
+			if len(p.query) > 0 {
+				p.query = p.query[:len(p.query)-1]
+				p.filter()
+			}
+		case "ctrl-u":
+			p.query = nil
+			p.filter()
+		case "ctrl-w":
+			q := strings.TrimRight(string(p.query), " ")
+			if i := strings.LastIndexAny(q, " /-_"); i >= 0 {
+				p.query = []rune(q[:i])
+			} else {
+				p.query = nil
+			}
+			p.filter()
+		case "", "left", "right":
+			// ignored
+		default:
+			if !strings.HasPrefix(key, "ctrl-") {
+				p.query = append(p.query, []rune(key)...)
+				p.filter()
+			}
+		}
+	}
+}
+
+func (p *picker) expects(key string) bool {
+	for _, k := range p.opts.Expect {
+		if k == key {
+			return true
+		}
+	}
+	return false
+}
+
+// filter recomputes the matches for the current query, best first.
+func (p *picker) filter() {
+	p.matches = p.matches[:0]
+	q := string(p.query)
+	for i, text := range p.plain {
+		if score, pos, ok := fuzzyMatch(q, text); ok {
+			p.matches = append(p.matches, match{index: i, score: score, positions: pos})
+		}
+	}
+	if q != "" {
+		sort.SliceStable(p.matches, func(i, j int) bool {
+			return p.matches[i].score > p.matches[j].score
+		})
+	}
+	p.cursor, p.offset = 0, 0
+}
+
+func (p *picker) move(delta int) {
+	p.cursor += delta
+	if p.cursor >= len(p.matches) {
+		p.cursor = len(p.matches) - 1
+	}
+	if p.cursor < 0 {
+		p.cursor = 0
+	}
+}
+
+func (p *picker) result(key string) *Result {
+	res := &Result{Key: key, Query: string(p.query)}
+	if p.opts.Multi {
+		for i := range p.labels {
+			if p.selected[i] {
+				res.Indices = append(res.Indices, i)
+			}
+		}
+	}
+	if len(res.Indices) == 0 && len(p.matches) > 0 {
+		res.Indices = []int{p.matches[p.cursor].index}
+	}
+	return res
+}
+
+// height is the number of list rows that fit below the prompt and header.
+func (p *picker) height(t *terminal) int {
+	h := t.rows - 2
+	if p.opts.Header != "" {
+		h--
+	}
+	if h < 1 {
+		h = 1
+	}
+	return h
+}
+
+func (p *picker) draw(t *terminal) {
+	h := p.height(t)
+	if p.cursor < p.offset {
+		p.offset = p.cursor
+	}
+	if p.cursor >= p.offset+h {
+		p.offset = p.cursor - h + 1
+	}
+
+	var sb strings.Builder
+	sb.WriteString("\033[H\033[2J")
+
+	// Prompt and match counter
+	fmt.Fprintf(&sb, "\033[1;36m>\033[0m %s\033[7m \033[0m\r\n", string(p.query))
+	counter := fmt.Sprintf("  %d/%d", len(p.matches), len(p.labels))
+	if n := countTrue(p.selected); p.opts.Multi && n > 0 {
+		counter += fmt.Sprintf(" (%d selected)", n)
+	}
+	// Lines after the counter start with the line break rather than end with
+	// it: a break after the bottom row would scroll the prompt off screen.
+	fmt.Fprintf(&sb, "\033[2m%s\033[0m", counter)
+	if p.opts.Header != "" {
+		fmt.Fprintf(&sb, "\r\n\033[2m%s\033[0m", highlight(p.opts.Header, nil, t.cols-2))
+	}
+
+	for row := 0; row < h && p.offset+row < len(p.matches); row++ {
+		i := p.offset + row
+		m := p.matches[i]
+		cursor, mark := "  ", " "
+		if i == p.cursor {
+			cursor = "\033[1;31m▌\033[0m "
+		}
+		if p.selected[m.index] {
+			mark = "\033[1;35m•\033[0m"
+		}
+		line := highlight(p.labels[m.index], m.positions, t.cols-4)
+		if i == p.cursor {
+			line = "\033[1m" + line
+		}
+		fmt.Fprintf(&sb, "\r\n%s%s%s", cursor, mark, line)
+	}
+
+	fmt.Fprint(t.tty, sb.String())
+}
+
+func countTrue(m map[int]bool) int {
+	n := 0
+	for _, v := range m {
+		if v {
+			n++
+		}
+	}
+	return n
+}
@@ -0,0 +1,43 @@
+package worktree
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/songtov/wtt/internal/git"
+)
+
+// Match returns the worktrees matching query, for "wtt <branch>" navigation.
+// Candidates are compared on both the short branch name and the worktree
+// directory name, in tiers of decreasing strictness:
+//
+//  1. exact match
+//  2. exact match of the last "/"-separated branch segment (login → feature/login)
+//  3. case-insensitive prefix match
+//  4. case-insensitive substring match
+//
+// The matches of the first tier that has any are returned, so a single result
+// means the query is unambiguous.
+func Match(worktrees []git.Worktree, query string) []git.Worktree {
+	q := strings.ToLower(query)
+	tiers := []func(name string) bool{
+		func(name string) bool { return name == query },
+		func(name string) bool { return name[strings.LastIndex(name, "/")+1:] == query },
+		func(name string) bool { return strings.HasPrefix(strings.ToLower(name), q) },
+		func(name string) bool { return strings.Contains(strings.ToLower(name), q) },
+	}
+
+	for _, match := range tiers {
+		var matches []git.Worktree
+		for _, wt := range worktrees {
+			branch := strings.TrimPrefix(wt.Branch, "refs/heads/")
+			if (branch != "" && match(branch)) || match(filepath.Base(wt.Path)) {
+				matches = append(matches, wt)
+			}
+		}
+		if len(matches) > 0 {
+			return matches
+		}
+	}
+	return nil
+}
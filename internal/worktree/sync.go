@@ -0,0 +1,110 @@
+package worktree
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/songtov/wtt/internal/git"
+)
+
+// SyncStatus describes the outcome of syncing a single worktree.
+type SyncStatus string
+
+const (
+	SyncUpdated    SyncStatus = "updated"
+	SyncUpToDate   SyncStatus = "up to date"
+	SyncDirty      SyncStatus = "skipped-dirty"
+	SyncConflicted SyncStatus = "conflicted"
+	SyncNoBase     SyncStatus = "skipped-no-base"
+	SyncFailed     SyncStatus = "failed"
+)
+
+// Sync brings the branch checked out at worktreePath up to date with base,
+// using strategy "rebase" or "merge". Dirty worktrees are left untouched.
+// On conflicts the rebase/merge is aborted so the worktree is returned to the
+// state it was in before Sync was called.
+func Sync(worktreePath, base, strategy string) (SyncStatus, error) {
+	if base == "" {
+		return SyncNoBase, nil
+	}
+
+	dirty, err := git.IsDirty(worktreePath)
+	if err != nil {
+		return SyncFailed, err
+	}
+	if dirty {
+		return SyncDirty, nil
+	}
+
+	if git.IsAncestor(worktreePath, base, "HEAD") {
+		return SyncUpToDate, nil
+	}
+
+	var args, abort []string
+	switch strategy {
+	case "rebase":
+		args = []string{"rebase", "--quiet", base}
+		abort = []string{"rebase", "--abort"}
+	case "merge":
+		args = []string{"merge", "--no-edit", "--quiet", base}
+		abort = []string{"merge", "--abort"}
+	default:
+		return SyncFailed, fmt.Errorf("unknown sync strategy %q (want rebase or merge)", strategy)
+	}
+
+	cmd := exec.Command("git", args...)
+	cmd.Dir = worktreePath
+	if out, err := cmd.CombinedOutput(); err != nil {
+		// Only unmerged paths mean a conflict; anything else (a bad base,
+		// a hook refusing, ...) is reported as a failure
+		conflicted := hasUnmergedPaths(worktreePath)
+		// A rebase or merge that stopped midway, for whatever reason, must
+		// not be left behind
+		if inProgress(worktreePath, strategy) {
+			abortCmd := exec.Command("git", abort...)
+			abortCmd.Dir = worktreePath
+			if abortOut, abortErr := abortCmd.CombinedOutput(); abortErr != nil {
+				return SyncFailed, fmt.Errorf("git %s failed and could not be aborted, the worktree is left mid-%s: %w\n%s%s", strategy, strategy, abortErr, out, abortOut)
+			}
+		}
+		if !conflicted {
+			return SyncFailed, fmt.Errorf("git %s: %w\n%s", strategy, err, out)
+		}
+		return SyncConflicted, nil
+	}
+	return SyncUpdated, nil
+}
+
+// inProgress reports whether a rebase or merge (per strategy) is under way
+// in the worktree at dir.
+func inProgress(dir, strategy string) bool {
+	markers := []string{"MERGE_HEAD"}
+	if strategy == "rebase" {
+		markers = []string{"rebase-merge", "rebase-apply"}
+	}
+	for _, m := range markers {
+		out, err := exec.Command("git", "-C", dir, "rev-parse", "--git-path", m).Output()
+		if err != nil {
+			continue
+		}
+		// The path is relative to dir unless it lies outside it
+		path := strings.TrimSpace(string(out))
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(dir, path)
+		}
+		if _, err := os.Stat(path); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// hasUnmergedPaths reports whether the index of the worktree at dir has
+// conflicted entries.
+func hasUnmergedPaths(dir string) bool {
+	out, err := exec.Command("git", "-C", dir, "ls-files", "--unmerged").Output()
+	return err == nil && len(out) > 0
+}
@@ -2,19 +2,22 @@ package worktree
 
 import (
 	"fmt"
+	"os"
 	"os/exec"
 	"path/filepath"
 
 	"github.com/songtov/wtt/internal/git"
 )
 
-// Create creates a new git worktree for the given branch and returns its path.
-// worktreeBaseDir is the absolute path of the base directory for worktrees.
-// base, if non-empty, is passed as the start-point to git worktree add.
-func Create(repoRoot, worktreeBaseDir, branch, base string) (string, error) {
-	safeName := git.BranchToPath(branch)
-	worktreePath := filepath.Join(worktreeBaseDir, safeName)
+// Path returns where the worktree for branch goes under worktreeBaseDir.
+func Path(worktreeBaseDir, branch string) string {
+	return filepath.Join(worktreeBaseDir, git.BranchToPath(branch))
+}
 
+// Create creates a new git worktree for the given branch at the absolute
+// path worktreePath. base, if non-empty, is passed as the start-point to
+// git worktree add.
+func Create(repoRoot, worktreePath, branch, base string) error {
 	args := []string{"worktree", "add", "-b", branch, worktreePath}
 	if base != "" {
 		args = append(args, base)
@@ -23,10 +26,26 @@ func Create(repoRoot, worktreeBaseDir, branch, base string) (string, error) {
 	cmd.Dir = repoRoot
 	out, err := cmd.CombinedOutput()
 	if err != nil {
-		return "", fmt.Errorf("git worktree add: %w\n%s", err, out)
+		return fmt.Errorf("git worktree add: %w\n%s", err, out)
 	}
+	return nil
+}
 
-	return worktreePath, nil
+// Checkout creates a worktree at worktreePath for a branch that already
+// exists. If remote is non-empty (e.g. "origin/feature/login") the branch
+// only exists remotely and a local branch tracking it is created.
+func Checkout(repoRoot, worktreePath, branch, remote string) error {
+	args := []string{"worktree", "add", worktreePath, branch}
+	if remote != "" {
+		args = []string{"worktree", "add", "--track", "-b", branch, worktreePath, remote}
+	}
+	cmd := exec.Command("git", args...)
+	cmd.Dir = repoRoot
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("git worktree add: %w\n%s", err, out)
+	}
+	return nil
 }
 
 // Remove removes the git worktree at the given path.
@@ -46,3 +65,91 @@ func Remove(repoRoot, worktreePath string, force bool) error {
 	}
 	return nil
 }
+
+// Adopt registers the existing directory dir, which git no longer knows as
+// a worktree, as the worktree of branch without touching its files. The
+// branch is created from HEAD if it doesn't exist. Afterwards the directory's
+// files show up as changes against the branch. If adopting fails midway, the
+// worktree registration and any branch it created are undone.
+func Adopt(repoRoot, dir, branch string) error {
+	// git worktree repair, used below, came with git 2.29
+	if major, minor, version, err := git.Version(); err == nil && (major < 2 || major == 2 && minor < 29) {
+		return fmt.Errorf("adopting needs git 2.29 or newer, found %s", version)
+	}
+
+	// git worktree add refuses non-empty directories, so set up the
+	// worktree's administrative files in an empty one of the same name and
+	// move its .git file over
+	tmpParent, err := os.MkdirTemp(filepath.Dir(dir), ".wtt-adopt-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmpParent)
+	tmp := filepath.Join(tmpParent, filepath.Base(dir))
+
+	newBranch := !git.BranchExists(repoRoot, branch)
+	args := []string{"worktree", "add", "--no-checkout", tmp, branch}
+	if newBranch {
+		args = []string{"worktree", "add", "--no-checkout", "-b", branch, tmp}
+	}
+	if err := run(repoRoot, args...); err != nil {
+		return err
+	}
+
+	// A leftover .git file points at administrative files that are gone;
+	// keep it aside until the new one is in place
+	dotGit, oldDotGit := filepath.Join(dir, ".git"), filepath.Join(tmpParent, ".git.old")
+	admin, err := git.GitFileTarget(filepath.Join(tmp, ".git"))
+	if err == nil {
+		err = os.Rename(dotGit, oldDotGit)
+		if os.IsNotExist(err) {
+			err = nil
+		}
+	}
+	if err == nil {
+		err = os.Rename(filepath.Join(tmp, ".git"), dotGit)
+	}
+	if err == nil {
+		err = run(repoRoot, "worktree", "repair", dir)
+	}
+	if err == nil {
+		// Fill the index from the branch so only real differences show
+		err = run(dir, "reset", "--quiet")
+	}
+	if err != nil {
+		undoAdopt(repoRoot, tmp, dir, admin, oldDotGit, branch, newBranch)
+	}
+	return err
+}
+
+// undoAdopt takes back a failed Adopt: it drops the worktree's
+// administrative files, wherever its .git file ended up, restores dir's old
+// .git file and deletes the branch if Adopt created it.
+func undoAdopt(repoRoot, tmp, dir, admin, oldDotGit, branch string, newBranch bool) {
+	if admin == "" {
+		// Nothing moved yet; git can still find the worktree at tmp
+		_ = run(repoRoot, "worktree", "remove", "--force", tmp)
+	} else {
+		if target, err := git.GitFileTarget(filepath.Join(dir, ".git")); err == nil && target == admin {
+			_ = os.Remove(filepath.Join(dir, ".git"))
+		}
+		_ = os.RemoveAll(admin)
+	}
+	if _, err := os.Lstat(oldDotGit); err == nil {
+		_ = os.Rename(oldDotGit, filepath.Join(dir, ".git"))
+	}
+	if newBranch {
+		_ = git.DeleteBranch(repoRoot, branch)
+	}
+}
+
+// run runs a git subcommand (e.g. "worktree add ...") in dir.
+func run(dir string, args ...string) error {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("git %s %s: %w\n%s", args[0], args[1], err, out)
+	}
+	return nil
+}